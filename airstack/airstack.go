@@ -4,24 +4,45 @@ package airstack
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"net/http"
-	"strings"
+
+	"example.com/ethgotools/internal/httpretry"
+)
+
+// Endpoint is the URL of the Airstack GraphQL API, used unless the client
+// has another BaseURL.
+const Endpoint = "https://api.airstack.xyz/gql"
+
+// Defaults of the timeout and retry settings of a client from NewClient.
+const (
+	DefaultTimeout        = httpretry.DefaultTimeout
+	DefaultMaxRetries     = httpretry.DefaultMaxRetries
+	DefaultRetryBaseDelay = httpretry.DefaultRetryBaseDelay
+	DefaultMaxRetryDelay  = httpretry.DefaultMaxRetryDelay
 )
 
 // AirstackClient represents a client for the Airstack API
 type AirstackClient struct {
 	APIKey     string
 	HTTPClient *http.Client
+	// BaseURL is the GraphQL endpoint requests are sent to, such as a
+	// proxy, a staging API or a local fake. Empty means Endpoint.
+	BaseURL string
+	// Policy sets the timeout of each attempt of a request and how failed
+	// attempts are retried.
+	httpretry.Policy
 }
 
 // NewClient creates a new instance of AirstackClient
 func NewClient() *AirstackClient {
 	return &AirstackClient{
 		HTTPClient: &http.Client{},
+		BaseURL:    Endpoint,
+		Policy:     httpretry.DefaultPolicy(),
 	}
 }
 
@@ -30,115 +51,83 @@ func (c *AirstackClient) SetAPIKey(apiKey string) {
 	c.APIKey = apiKey
 }
 
-// FarcasterResponse represents the structure of the Farcaster API response
-type FarcasterResponse struct {
-	Data struct {
-		Socials struct {
-			Social []struct {
-				ProfileName    string `json:"profileName"`
-				FollowerCount  int    `json:"followerCount"`
-				FollowingCount int    `json:"followingCount"`
-				FarcasterScore struct {
-					FarScore float64 `json:"farScore"`
-				} `json:"farcasterScore"`
-			} `json:"Social"`
-		} `json:"Socials"`
-		FarcasterCasts struct {
-			Cast []struct {
-				Text string `json:"text"`
-				Hash string `json:"hash"`
-			} `json:"Cast"`
-		} `json:"FarcasterCasts"`
-	} `json:"data"`
+// SetBaseURL sets the GraphQL endpoint of the Airstack client
+func (c *AirstackClient) SetBaseURL(baseURL string) {
+	c.BaseURL = baseURL
 }
 
-// QueryFarcasterAccount queries the Airstack API for Farcaster account information
-func (c *AirstackClient) QueryFarcasterAccount(fname string) (*FarcasterResponse, error) {
-	if c.APIKey == "" {
-		return nil, errors.New("airstack API key not set")
-	}
-
-	// Prepare the GraphQL query with the fname variable
-	query := fmt.Sprintf(`
-		query MyQuery {
-		  Socials(
-			input: {
-			  filter: { dappName: { _eq: farcaster }, identity: { _eq: "fc_fname:%s" } }
-			  blockchain: ethereum
-			}
-		  ) {
-			Social {
-			  profileName
-			  followerCount
-			  followingCount
-			  farcasterScore {
-			    farScore
-			  }
-			}
-		  }
-		  FarcasterCasts(
-			input: {
-			  blockchain: ALL,
-			  filter: { castedBy: { _eq: "fc_fname:%s" } },
-			  limit: 5
-			}
-		  ) {
-			Cast {
-			  text
-			  hash
-			}
-		  }
-		}
-	`, fname, fname)
+// Request is the body of a GraphQL request. User input goes in Variables,
+// never in Query, so it cannot change the meaning of the query document.
+type Request struct {
+	Query     string `json:"query"`
+	Variables any    `json:"variables,omitempty"`
+}
 
-	// Clean up the query string
-	cleanQuery := strings.ReplaceAll(query, "\n", " ")
-	cleanQuery = strings.ReplaceAll(cleanQuery, "\t", " ")
-	cleanQuery = strings.TrimSpace(cleanQuery)
+// Query is like QueryContext with a background context.
+func (c *AirstackClient) Query(query string, variables any, result any) error {
+	return c.QueryContext(context.Background(), query, variables, result)
+}
 
-	// Create the request payload
-	payload := map[string]string{
-		"query": cleanQuery,
+// QueryContext sends a GraphQL query document with its variables and
+// decodes the data of the response into result. Transient failures are
+// retried until ctx is done.
+//
+// Errors in the response are returned as a *ResponseError, after decoding
+// any partial data into result. Failures can be matched against the
+// sentinel errors of the package with errors.Is.
+func (c *AirstackClient) QueryContext(ctx context.Context, query string, variables any, result any) error {
+	if c.APIKey == "" {
+		return ErrNoAPIKey
 	}
 
-	// Marshal the payload to JSON
-	payloadBytes, err := json.Marshal(payload)
+	payloadBytes, err := json.Marshal(Request{Query: query, Variables: variables})
 	if err != nil {
-		return nil, fmt.Errorf("error marshaling payload: %w", err)
+		return fmt.Errorf("error marshaling payload: %w", err)
 	}
 
-	jsonData := bytes.NewReader(payloadBytes)
-
-	req, err := http.NewRequest("POST", "https://api.airstack.xyz/gql", jsonData)
+	bodyBytes, err := c.post(ctx, payloadBytes)
 	if err != nil {
-		return nil, fmt.Errorf("error creating request: %w", err)
+		return err
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", c.APIKey)
-
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("error sending request: %w", err)
+	var response struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []GraphQLError  `json:"errors"`
 	}
-	defer resp.Body.Close()
-
-	bodyBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("error reading response body: %w", err)
+	if err := json.Unmarshal(bodyBytes, &response); err != nil {
+		return fmt.Errorf("error parsing JSON response: %w", err)
 	}
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API request failed with status code %d: %s", resp.StatusCode, string(bodyBytes))
+	if len(response.Data) > 0 && string(response.Data) != "null" {
+		if err := json.Unmarshal(response.Data, result); err != nil {
+			return fmt.Errorf("error parsing JSON response data: %w", err)
+		}
 	}
-
-	var result FarcasterResponse
-	err = json.Unmarshal(bodyBytes, &result)
-	if err != nil {
-		// Print the body for debugging
-		fmt.Printf("Error parsing JSON response: %v\nResponse Body: %s\n", err, string(bodyBytes))
-		return nil, fmt.Errorf("error parsing JSON response: %w", err)
+	if len(response.Errors) > 0 {
+		return &ResponseError{Errors: response.Errors}
 	}
+	return nil
+}
 
-	return &result, nil
+// post sends payload with the retry policy of the client and returns the
+// response body.
+func (c *AirstackClient) post(ctx context.Context, payload []byte) ([]byte, error) {
+	endpoint := c.BaseURL
+	if endpoint == "" {
+		endpoint = Endpoint
+	}
+	body, err := c.Policy.Do(ctx, c.HTTPClient, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", c.APIKey)
+		return req, nil
+	})
+
+	var status *httpretry.StatusError
+	if errors.As(err, &status) {
+		return nil, &StatusError{status}
+	}
+	return body, err
 }
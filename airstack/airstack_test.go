@@ -0,0 +1,164 @@
+package airstack
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// roundTripFunc serves requests of an http.Client without a network.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestQueryFarcasterProfileVariables(t *testing.T) {
+	// Quotes and braces in the name must not end up in the query document
+	fname := `evil" } } Socials { Social { userId`
+
+	var request struct {
+		Query     string                    `json:"query"`
+		Variables FarcasterAccountVariables `json:"variables"`
+	}
+	client := NewClient()
+	client.SetAPIKey("test-key")
+	client.HTTPClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Header.Get("Authorization") != "test-key" {
+			t.Errorf("Expected the API key to be sent, got %q", req.Header.Get("Authorization"))
+		}
+		if err := json.NewDecoder(req.Body).Decode(&request); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		body := `{"data":{"Socials":{"Social":[{"profileName":"evil","followerCount":3}]}}}`
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body))}, nil
+	})
+
+	result, err := client.QueryFarcasterProfileContext(context.Background(), fname)
+	if err != nil {
+		t.Fatalf("QueryFarcasterProfileContext failed: %v", err)
+	}
+	if request.Query != FarcasterProfileQuery {
+		t.Errorf("Expected the query document to be sent unchanged, got %q", request.Query)
+	}
+	if request.Variables.Identity != "fc_fname:"+fname || request.Variables.Limit != 0 {
+		t.Errorf("Unexpected variables %+v", request.Variables)
+	}
+	if result.ProfileName != "evil" || result.FollowerCount != 3 {
+		t.Errorf("Unexpected result %+v", result)
+	}
+}
+
+func TestQueryRequiresAPIKey(t *testing.T) {
+	if _, err := NewClient().QueryFarcasterProfileContext(context.Background(), "dwr"); err == nil {
+		t.Error("Expected an error without an API key")
+	}
+}
+
+// response returns a stub response with the given status, headers and body.
+func response(status int, body string, header ...string) *http.Response {
+	resp := &http.Response{StatusCode: status, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(body))}
+	for i := 0; i+1 < len(header); i += 2 {
+		resp.Header.Set(header[i], header[i+1])
+	}
+	return resp
+}
+
+func TestQueryRetries(t *testing.T) {
+	var attempts int
+	var waited []time.Time
+	client := NewClient()
+	client.SetAPIKey("test-key")
+	client.RetryBaseDelay = time.Millisecond
+	client.HTTPClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		attempts++
+		waited = append(waited, time.Now())
+		switch attempts {
+		case 1:
+			return response(http.StatusTooManyRequests, "slow down", "Retry-After", "1"), nil
+		case 2:
+			return nil, errors.New("connection reset")
+		case 3:
+			return response(http.StatusBadGateway, "bad gateway"), nil
+		}
+		return response(http.StatusOK, `{"data":{"Socials":{"Social":[{"profileName":"dwr"}]}}}`), nil
+	})
+
+	if _, err := client.QueryFarcasterProfileContext(context.Background(), "dwr"); err != nil {
+		t.Fatalf("Expected the request to succeed after retries, got %v", err)
+	}
+	if attempts != 4 {
+		t.Errorf("Expected 4 attempts, got %d", attempts)
+	}
+	if d := waited[1].Sub(waited[0]); d < time.Second {
+		t.Errorf("Expected Retry-After to be honored, retried after %s", d)
+	}
+
+	// Client errors are not retried, and retries stop after MaxRetries
+	for status, want := range map[int]int{http.StatusBadRequest: 1, http.StatusServiceUnavailable: 4} {
+		attempts = 0
+		client.HTTPClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			attempts++
+			return response(status, "failed"), nil
+		})
+		if _, err := client.QueryFarcasterProfileContext(context.Background(), "dwr"); err == nil || attempts != want {
+			t.Errorf("Status %d: expected an error after %d attempts, got %v after %d", status, want, err, attempts)
+		}
+	}
+}
+
+func TestRetryAfterCapped(t *testing.T) {
+	var attempts int
+	client := NewClient()
+	client.SetAPIKey("test-key")
+	client.MaxRetryDelay = 50 * time.Millisecond
+	client.HTTPClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		attempts++
+		if attempts == 1 {
+			return response(http.StatusServiceUnavailable, "down for maintenance", "Retry-After", "3600"), nil
+		}
+		return response(http.StatusOK, `{"data":{"Socials":{"Social":[{"profileName":"dwr"}]}}}`), nil
+	})
+
+	start := time.Now()
+	if _, err := client.QueryFarcasterProfileContext(context.Background(), "dwr"); err != nil {
+		t.Fatalf("Expected the request to succeed after a retry, got %v", err)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("Expected Retry-After to be capped at MaxRetryDelay, waited %s", d)
+	}
+}
+
+func TestQueryContextCancel(t *testing.T) {
+	client := NewClient()
+	client.SetAPIKey("test-key")
+	client.Timeout = 20 * time.Millisecond
+	client.MaxRetries = 1
+	client.RetryBaseDelay = time.Millisecond
+	var attempts int
+	client.HTTPClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		// Hang until the request is given up on
+		attempts++
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	})
+
+	// Timed out attempts are retried
+	if _, err := client.QueryFarcasterProfileContext(context.Background(), "dwr"); !errors.Is(err, context.DeadlineExceeded) || attempts != 2 {
+		t.Errorf("Expected both attempts to time out, got %v after %d", err, attempts)
+	}
+
+	// A cancelled request is not
+	client.Timeout = 0
+	attempts = 0
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	if _, err := client.QueryFarcasterProfileContext(ctx, "dwr"); !errors.Is(err, context.Canceled) || attempts != 1 {
+		t.Errorf("Expected the request to be cancelled, got %v after %d", err, attempts)
+	}
+}
@@ -0,0 +1,181 @@
+// airstacktest.go
+
+// Package airstacktest provides a fake Airstack GraphQL API for tests that
+// must run offline. It answers the Farcaster queries of package airstack
+// with the recorded responses in fixtures/, one per Farcaster name (such as
+// "dwr" and "v") that can also be looked up by the fid of its profile, and
+// with empty data for any other identity. Lists of casts and channels are
+// cut to the limit variable of the query.
+package airstacktest
+
+import (
+	"embed"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"example.com/ethgotools/airstack"
+)
+
+// APIKey is the only API key the server accepts.
+const APIKey = "airstacktest-key"
+
+//go:embed fixtures/*.json
+var fixtures embed.FS
+
+// emptyResponse is the answer for identities without a fixture.
+const emptyResponse = `{"data":{"Socials":{"Social":null},"FarcasterCasts":{"Cast":null},"FarcasterChannelParticipants":{"FarcasterChannelParticipant":null}}}`
+
+// Server is a fake Airstack GraphQL API running on a local port. Close it
+// when done.
+type Server struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	responses map[string][]byte // by identity, such as "fc_fname:dwr" or "fc_fid:3"
+	failures  []int
+	requests  []airstack.Request
+}
+
+// NewServer starts a server that answers with the recorded fixtures.
+func NewServer() *Server {
+	s := &Server{responses: make(map[string][]byte)}
+	entries, _ := fixtures.ReadDir("fixtures")
+	for _, entry := range entries {
+		body, _ := fixtures.ReadFile(path.Join("fixtures", entry.Name()))
+		s.setResponse(strings.TrimSuffix(entry.Name(), ".json"), body)
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// AirstackClient returns a client for the server. It retries without
+// delay so tests of failures stay fast.
+func (s *Server) AirstackClient() *airstack.AirstackClient {
+	client := airstack.NewClient()
+	client.SetAPIKey(APIKey)
+	client.SetBaseURL(s.URL)
+	client.HTTPClient = s.Client()
+	client.RetryBaseDelay = time.Millisecond
+	return client
+}
+
+// SetResponse makes the server answer queries for the Farcaster name fname,
+// and for the fid of the profile in body if there is one, with body, a
+// complete GraphQL response.
+func (s *Server) SetResponse(fname, body string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.setResponse(fname, []byte(body))
+}
+
+func (s *Server) setResponse(fname string, body []byte) {
+	s.responses["fc_fname:"+fname] = body
+	var response struct {
+		Data struct {
+			Socials struct {
+				Social []struct {
+					UserID string `json:"userId"`
+				}
+			}
+		}
+	}
+	if json.Unmarshal(body, &response) == nil && len(response.Data.Socials.Social) > 0 {
+		s.responses["fc_fid:"+response.Data.Socials.Social[0].UserID] = body
+	}
+}
+
+// queries are the query documents the server knows. Each is answered with
+// the whole recorded response, of which clients decode what they asked for.
+var queries = map[string]bool{
+	airstack.FarcasterProfileQuery:  true,
+	airstack.FarcasterCastsQuery:    true,
+	airstack.FarcasterChannelsQuery: true,
+}
+
+// FailNext makes the server answer the next requests with the given HTTP
+// statuses, one per request, before answering normally again.
+func (s *Server) FailNext(statuses ...int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, statuses...)
+}
+
+// Requests returns the requests received so far, including failed ones.
+func (s *Server) Requests() []airstack.Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]airstack.Request(nil), s.requests...)
+}
+
+// limitResponse cuts the lists in the data of a GraphQL response, other than
+// the profile, to limit entries, as the limit variable of the queries does.
+func limitResponse(body []byte, limit int) []byte {
+	var response struct {
+		Data map[string]map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return body
+	}
+	for name, fields := range response.Data {
+		if name == "Socials" {
+			continue
+		}
+		for field, value := range fields {
+			var list []json.RawMessage
+			if json.Unmarshal(value, &list) == nil && len(list) > limit {
+				fields[field], _ = json.Marshal(list[:limit])
+			}
+		}
+	}
+	limited, err := json.Marshal(response)
+	if err != nil {
+		return body
+	}
+	return limited
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var request struct {
+		Query     string                             `json:"query"`
+		Variables airstack.FarcasterAccountVariables `json:"variables"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, airstack.Request{Query: request.Query, Variables: request.Variables})
+	status := http.StatusOK
+	if len(s.failures) > 0 {
+		status, s.failures = s.failures[0], s.failures[1:]
+	}
+	body, found := s.responses[request.Variables.Identity]
+	s.mu.Unlock()
+
+	switch {
+	case status != http.StatusOK:
+		http.Error(w, http.StatusText(status), status)
+		return
+	case r.Header.Get("Authorization") != APIKey:
+		http.Error(w, "invalid API key", http.StatusUnauthorized)
+		return
+	case !queries[request.Query]:
+		body = []byte(`{"data":null,"errors":[{"message":"unknown query document","extensions":{"code":"GRAPHQL_VALIDATION_FAILED"}}]}`)
+	case !found:
+		body = []byte(emptyResponse)
+	case request.Variables.Limit > 0:
+		body = limitResponse(body, request.Variables.Limit)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}
@@ -0,0 +1,117 @@
+// errors.go
+
+package airstack
+
+import (
+	"errors"
+	"strings"
+
+	"example.com/ethgotools/internal/httpretry"
+)
+
+// Errors that failed requests can be matched against with errors.Is. They
+// are reported by StatusError and ResponseError, which carry the details.
+var (
+	// ErrNoAPIKey is returned when the client has no API key.
+	ErrNoAPIKey = errors.New("airstack API key not set")
+	// ErrUnauthorized means the API key was missing, invalid or not allowed
+	// to run the query.
+	ErrUnauthorized = errors.New("airstack: unauthorized")
+	// ErrRateLimited means too many requests were made with the API key.
+	ErrRateLimited = errors.New("airstack: rate limited")
+	// ErrNotFound means the queried account or resource does not exist.
+	ErrNotFound = errors.New("airstack: not found")
+	// ErrSchema means the query does not match the schema of the API, for
+	// example after a field was removed.
+	ErrSchema = errors.New("airstack: query does not match the schema")
+)
+
+// StatusError is a response with an HTTP status other than 200 OK.
+type StatusError struct {
+	*httpretry.StatusError
+}
+
+// statusSentinels are the errors a StatusError is matched against.
+var statusSentinels = httpretry.Sentinels{Unauthorized: ErrUnauthorized, RateLimited: ErrRateLimited, NotFound: ErrNotFound}
+
+// Is matches the status code against the sentinel errors.
+func (e *StatusError) Is(target error) bool {
+	return e.Matches(target, statusSentinels)
+}
+
+func (e *StatusError) Unwrap() error {
+	return e.StatusError
+}
+
+// GraphQLError is an entry of the errors array of a GraphQL response.
+type GraphQLError struct {
+	Message   string `json:"message"`
+	Path      []any  `json:"path,omitempty"`
+	Locations []struct {
+		Line   int `json:"line"`
+		Column int `json:"column"`
+	} `json:"locations,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+// Code returns the error code in the extensions of the error, such as
+// UNAUTHENTICATED, or "" if there is none.
+func (e GraphQLError) Code() string {
+	code, _ := e.Extensions["code"].(string)
+	return strings.ToUpper(code)
+}
+
+// kind returns the sentinel error e corresponds to, or nil. The code is used
+// when the server sets one, otherwise the message is matched against the
+// wording of common GraphQL servers.
+func (e GraphQLError) kind() error {
+	switch e.Code() {
+	case "UNAUTHENTICATED", "UNAUTHORIZED", "FORBIDDEN":
+		return ErrUnauthorized
+	case "RATE_LIMITED", "TOO_MANY_REQUESTS":
+		return ErrRateLimited
+	case "NOT_FOUND":
+		return ErrNotFound
+	case "GRAPHQL_VALIDATION_FAILED", "GRAPHQL_PARSE_FAILED", "BAD_USER_INPUT":
+		return ErrSchema
+	}
+
+	message := strings.ToLower(e.Message)
+	switch {
+	case strings.Contains(message, "unauthorized"), strings.Contains(message, "unauthenticated"),
+		strings.Contains(message, "api key"):
+		return ErrUnauthorized
+	case strings.Contains(message, "rate limit"), strings.Contains(message, "too many requests"):
+		return ErrRateLimited
+	case strings.Contains(message, "not found"):
+		return ErrNotFound
+	case strings.Contains(message, "cannot query field"), strings.Contains(message, "unknown argument"),
+		strings.Contains(message, "unknown type"), strings.Contains(message, "syntax error"):
+		return ErrSchema
+	}
+	return nil
+}
+
+// ResponseError is a GraphQL response that came back with errors, which
+// servers send with a 200 OK status.
+type ResponseError struct {
+	Errors []GraphQLError
+}
+
+func (e *ResponseError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Message
+	}
+	return "GraphQL error: " + strings.Join(messages, "; ")
+}
+
+// Is reports whether any of the errors corresponds to target.
+func (e *ResponseError) Is(target error) bool {
+	for _, err := range e.Errors {
+		if err.kind() == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package airstack
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"testing"
+)
+
+// stubClient returns a client whose requests are all answered with status
+// and body.
+func stubClient(status int, body string) *AirstackClient {
+	client := NewClient()
+	client.SetAPIKey("test-key")
+	client.MaxRetries = 0
+	client.HTTPClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return response(status, body), nil
+	})
+	return client
+}
+
+func TestGraphQLErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want error
+	}{
+		{"code", `{"data":null,"errors":[{"message":"denied","extensions":{"code":"UNAUTHENTICATED"}}]}`, ErrUnauthorized},
+		{"message", `{"errors":[{"message":"Cannot query field \"farScore\" on type \"Social\".","locations":[{"line":12,"column":9}]}]}`, ErrSchema},
+		{"rate limit", `{"errors":[{"message":"Rate limit exceeded"}]}`, ErrRateLimited},
+	}
+	for _, tt := range tests {
+		_, err := stubClient(http.StatusOK, tt.body).QueryFarcasterProfileContext(context.Background(), "dwr")
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, err)
+		}
+		var responseErr *ResponseError
+		if !errors.As(err, &responseErr) || len(responseErr.Errors) != 1 {
+			t.Errorf("%s: expected a ResponseError, got %#v", tt.name, err)
+		}
+	}
+
+	// Errors are reported even when some data came back with them
+	var data struct {
+		Socials struct {
+			Social []struct{ ProfileName string }
+		}
+	}
+	err := stubClient(http.StatusOK, `{"data":{"Socials":{"Social":[{"profileName":"dwr"}]}},"errors":[{"message":"partial failure","path":["Socials","Social",0,"farcasterScore"]}]}`).
+		Query(FarcasterProfileQuery, nil, &data)
+	var responseErr *ResponseError
+	if !errors.As(err, &responseErr) || responseErr.Errors[0].Path[0] != "Socials" || len(data.Socials.Social) != 1 {
+		t.Errorf("Expected partial data with the error, got %v and %+v", err, data)
+	}
+}
+
+func TestStatusErrors(t *testing.T) {
+	for status, want := range map[int]error{
+		http.StatusUnauthorized:    ErrUnauthorized,
+		http.StatusForbidden:       ErrUnauthorized,
+		http.StatusTooManyRequests: ErrRateLimited,
+		http.StatusNotFound:        ErrNotFound,
+	} {
+		_, err := stubClient(status, "failed").QueryFarcasterProfileContext(context.Background(), "dwr")
+		var statusErr *StatusError
+		if !errors.Is(err, want) || !errors.As(err, &statusErr) || statusErr.StatusCode != status {
+			t.Errorf("Status %d: expected %v, got %v", status, want, err)
+		}
+	}
+
+	if _, err := NewClient().QueryFarcasterProfileContext(context.Background(), "dwr"); !errors.Is(err, ErrNoAPIKey) {
+		t.Errorf("Expected ErrNoAPIKey, got %v", err)
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	_, err := stubClient(http.StatusOK, `{"data":{"Socials":{"Social":null}}}`).QueryFarcasterProfileContext(context.Background(), "nobody")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for an account without a profile, got %v", err)
+	}
+}
+
+func TestInvalidResponseIsNotPrinted(t *testing.T) {
+	// Anything printed would corrupt the TUI
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	_, queryErr := stubClient(http.StatusOK, "<html>gateway</html>").QueryFarcasterProfileContext(context.Background(), "dwr")
+	os.Stdout = stdout
+	w.Close()
+	printed, _ := io.ReadAll(r)
+
+	if queryErr == nil {
+		t.Error("Expected an error for a response that is not JSON")
+	}
+	if len(printed) != 0 {
+		t.Errorf("Expected nothing to be printed, got %q", printed)
+	}
+}
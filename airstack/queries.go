@@ -0,0 +1,174 @@
+// queries.go
+
+package airstack
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// FarcasterAccountVariables are the variables of the Farcaster queries.
+type FarcasterAccountVariables struct {
+	Identity string `json:"identity"`
+	Limit    int    `json:"limit,omitempty"`
+}
+
+// FarcasterProfileQuery looks up the Farcaster profile of $identity, such as
+// "fc_fname:dwr".
+const FarcasterProfileQuery = `query FarcasterProfile($identity: Identity!) {
+  Socials(
+    input: {
+      filter: { dappName: { _eq: farcaster }, identity: { _eq: $identity } }
+      blockchain: ethereum
+    }
+  ) {
+    Social {
+      userId
+      profileName
+      profileDisplayName
+      profileBio
+      followerCount
+      followingCount
+      farcasterScore {
+        farScore
+      }
+    }
+  }
+}`
+
+// FarcasterCastsQuery looks up the latest casts of $identity, such as
+// "fc_fid:3".
+const FarcasterCastsQuery = `query FarcasterCasts($identity: Identity!, $limit: Int) {
+  FarcasterCasts(
+    input: {
+      blockchain: ALL
+      filter: { castedBy: { _eq: $identity } }
+      limit: $limit
+    }
+  ) {
+    Cast {
+      text
+      hash
+      castedAtTimestamp
+      channel {
+        channelId
+      }
+    }
+  }
+}`
+
+// FarcasterChannelsQuery looks up the channels $identity, such as
+// "fc_fid:3", takes part in.
+const FarcasterChannelsQuery = `query FarcasterChannels($identity: Identity!, $limit: Int) {
+  FarcasterChannelParticipants(
+    input: {
+      filter: { participant: { _eq: $identity } }
+      blockchain: ALL
+      limit: $limit
+    }
+  ) {
+    FarcasterChannelParticipant {
+      channel {
+        channelId
+        name
+        description
+        followerCount
+        url
+      }
+    }
+  }
+}`
+
+// FarcasterSocial is a Farcaster profile returned by FarcasterProfileQuery.
+type FarcasterSocial struct {
+	UserID             string `json:"userId"`
+	ProfileName        string `json:"profileName"`
+	ProfileDisplayName string `json:"profileDisplayName"`
+	ProfileBio         string `json:"profileBio"`
+	FollowerCount      int    `json:"followerCount"`
+	FollowingCount     int    `json:"followingCount"`
+	FarcasterScore     *struct {
+		FarScore float64 `json:"farScore"`
+	} `json:"farcasterScore"`
+}
+
+// FarcasterCast is a cast returned by FarcasterCastsQuery.
+type FarcasterCast struct {
+	Text              string `json:"text"`
+	Hash              string `json:"hash"`
+	CastedAtTimestamp string `json:"castedAtTimestamp"`
+	Channel           *struct {
+		ChannelID string `json:"channelId"`
+	} `json:"channel"`
+}
+
+// FarcasterChannel is a channel returned by FarcasterChannelsQuery.
+type FarcasterChannel struct {
+	ChannelID     string `json:"channelId"`
+	Name          string `json:"name"`
+	Description   string `json:"description"`
+	FollowerCount int    `json:"followerCount"`
+	URL           string `json:"url"`
+}
+
+// QueryFarcasterProfileContext returns the profile of the Farcaster account
+// named fname, or ErrNotFound if there is none.
+func (c *AirstackClient) QueryFarcasterProfileContext(ctx context.Context, fname string) (*FarcasterSocial, error) {
+	var result struct {
+		Socials struct {
+			Social []FarcasterSocial `json:"Social"`
+		} `json:"Socials"`
+	}
+	variables := FarcasterAccountVariables{Identity: "fc_fname:" + fname}
+	if err := c.QueryContext(ctx, FarcasterProfileQuery, variables, &result); err != nil {
+		return nil, err
+	}
+	if len(result.Socials.Social) == 0 {
+		return nil, fmt.Errorf("no Farcaster account named %q: %w", fname, ErrNotFound)
+	}
+	return &result.Socials.Social[0], nil
+}
+
+// QueryFarcasterCastsContext returns up to limit of the latest casts of the
+// Farcaster account with the given fid.
+func (c *AirstackClient) QueryFarcasterCastsContext(ctx context.Context, fid uint64, limit int) ([]FarcasterCast, error) {
+	var result struct {
+		FarcasterCasts struct {
+			Cast []FarcasterCast `json:"Cast"`
+		} `json:"FarcasterCasts"`
+	}
+	variables := FarcasterAccountVariables{Identity: fidIdentity(fid), Limit: limit}
+	if err := c.QueryContext(ctx, FarcasterCastsQuery, variables, &result); err != nil {
+		return nil, err
+	}
+	return result.FarcasterCasts.Cast, nil
+}
+
+// QueryFarcasterChannelsContext returns up to limit of the channels the
+// Farcaster account with the given fid takes part in.
+func (c *AirstackClient) QueryFarcasterChannelsContext(ctx context.Context, fid uint64, limit int) ([]FarcasterChannel, error) {
+	var result struct {
+		FarcasterChannelParticipants struct {
+			FarcasterChannelParticipant []struct {
+				Channel *FarcasterChannel `json:"channel"`
+			} `json:"FarcasterChannelParticipant"`
+		} `json:"FarcasterChannelParticipants"`
+	}
+	variables := FarcasterAccountVariables{Identity: fidIdentity(fid), Limit: limit}
+	if err := c.QueryContext(ctx, FarcasterChannelsQuery, variables, &result); err != nil {
+		return nil, err
+	}
+	var channels []FarcasterChannel
+	for _, participant := range result.FarcasterChannelParticipants.FarcasterChannelParticipant {
+		if participant.Channel != nil {
+			channels = append(channels, *participant.Channel)
+		}
+	}
+	return channels, nil
+}
+
+// fidIdentity returns the Airstack identity of a Farcaster ID.
+func fidIdentity(fid uint64) string {
+	return "fc_fid:" + strconv.FormatUint(fid, 10)
+}
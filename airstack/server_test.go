@@ -0,0 +1,98 @@
+package airstack_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"example.com/ethgotools/airstack"
+	"example.com/ethgotools/airstack/airstacktest"
+)
+
+func TestClientAgainstFakeServer(t *testing.T) {
+	server := airstacktest.NewServer()
+	defer server.Close()
+	client := server.AirstackClient()
+	ctx := context.Background()
+
+	profile, err := client.QueryFarcasterProfileContext(ctx, "dwr")
+	if err != nil {
+		t.Fatalf("QueryFarcasterProfileContext failed: %v", err)
+	}
+	if profile.ProfileName != "dwr.eth" || profile.FarcasterScore.FarScore != 98.41 {
+		t.Errorf("Unexpected profile %+v", profile)
+	}
+
+	// Accounts without casts still have a profile
+	if profile, err := client.QueryFarcasterProfileContext(ctx, "v"); err != nil || profile.ProfileName != "v" {
+		t.Errorf("Expected a profile without casts, got %+v, %v", profile, err)
+	}
+
+	// Names the server has no fixture for do not exist
+	if _, err := client.QueryFarcasterProfileContext(ctx, `nobody" }`); !errors.Is(err, airstack.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+
+	requests := server.Requests()
+	if len(requests) != 3 || requests[2].Variables.(airstack.FarcasterAccountVariables).Identity != `fc_fname:nobody" }` {
+		t.Errorf("Unexpected requests %+v", requests)
+	}
+}
+
+func TestFarcasterQueriesAgainstFakeServer(t *testing.T) {
+	server := airstacktest.NewServer()
+	defer server.Close()
+	client := server.AirstackClient()
+	ctx := context.Background()
+
+	profile, err := client.QueryFarcasterProfileContext(ctx, "dwr")
+	if err != nil || profile.UserID != "3" || profile.ProfileDisplayName != "Dan Romero" || profile.FarcasterScore.FarScore != 98.41 {
+		t.Fatalf("Unexpected profile %+v, %v", profile, err)
+	}
+	if _, err := client.QueryFarcasterProfileContext(ctx, "nobody"); !errors.Is(err, airstack.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+
+	// The fixture has three casts and two channels, cut to the limit
+	casts, err := client.QueryFarcasterCastsContext(ctx, 3, 2)
+	if err != nil || len(casts) != 2 || casts[0].Channel != nil || casts[1].Channel.ChannelID != "farcaster" {
+		t.Errorf("Unexpected casts %+v, %v", casts, err)
+	}
+	channels, err := client.QueryFarcasterChannelsContext(ctx, 3, 5)
+	if err != nil || len(channels) != 2 || channels[1].ChannelID != "product" {
+		t.Errorf("Unexpected channels %+v, %v", channels, err)
+	}
+
+	requests := server.Requests()
+	if variables := requests[len(requests)-2].Variables.(airstack.FarcasterAccountVariables); variables.Identity != "fc_fid:3" || variables.Limit != 2 {
+		t.Errorf("Unexpected variables %+v", variables)
+	}
+}
+
+func TestClientRetriesFakeServer(t *testing.T) {
+	server := airstacktest.NewServer()
+	defer server.Close()
+	client := server.AirstackClient()
+
+	server.FailNext(http.StatusServiceUnavailable, http.StatusTooManyRequests)
+	if _, err := client.QueryFarcasterProfileContext(context.Background(), "dwr"); err != nil || len(server.Requests()) != 3 {
+		t.Errorf("Expected success on the third attempt, got %v after %d", err, len(server.Requests()))
+	}
+
+	client.SetAPIKey("wrong-key")
+	if _, err := client.QueryFarcasterProfileContext(context.Background(), "dwr"); !errors.Is(err, airstack.ErrUnauthorized) {
+		t.Errorf("Expected ErrUnauthorized, got %v", err)
+	}
+}
+
+func TestClientSchemaError(t *testing.T) {
+	server := airstacktest.NewServer()
+	defer server.Close()
+
+	var data map[string]any
+	err := server.AirstackClient().Query(`query { Unknown { id } }`, nil, &data)
+	if !errors.Is(err, airstack.ErrSchema) {
+		t.Errorf("Expected ErrSchema, got %v", err)
+	}
+}
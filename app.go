@@ -3,12 +3,19 @@
 package main
 
 import (
+	"context"
+	"crypto/ecdsa"
 	"crypto/sha256"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strings"
+	"time"
 
 	"example.com/ethgotools/airstack"
+	"example.com/ethgotools/farcaster"
+	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -18,17 +25,13 @@ import (
 	"github.com/charmbracelet/lipgloss"
 )
 
+// model is the root of the TUI. It shows the menu of registered tools and
+// hands every message to the open screen.
 type model struct {
-	choices  []string
+	session  *session
 	cursor   int
-	selected string
+	screen   Screen
 	quitting bool
-	content  string
-	input    string
-	input2   string
-	input3   string
-	state    string
-	step     int
 }
 
 var titleStyle = lipgloss.NewStyle().
@@ -44,27 +47,30 @@ var inputStyle = lipgloss.NewStyle().
 	Bold(true).
 	Foreground(lipgloss.Color("#00FF7F"))
 
-func initialModel() model {
-	return model{
-		choices: []string{
-			"Convert Private Key to Address",
-			"Generate New Private Key",
-			"Check Farcaster Account",
-			"Sign Message with Private Key",
-			"Verify Signature",
-			"Quit",
-		},
-		state: "menu",
-	}
+func init() {
+	registerTool(10, "Convert Private Key to Address", newConvertScreen)
+	registerTool(20, "Generate New Private Key", generateKey)
+	registerTool(60, "Check Farcaster Account", newFarcasterScreen)
+	registerTool(70, "Sign Message with Private Key", newSignScreen)
+	registerTool(80, "Verify Signature", newVerifyScreen)
+	registerTool(90, "Recover Signer", newRecoverScreen)
+}
+
+func initialModel(out io.Writer) model {
+	return model{session: newSession(out)}
 }
 
 func main() {
 	err := godotenv.Load()
+	if len(os.Args) > 1 {
+		os.Exit(runCLI(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+	}
 	if err != nil {
 		fmt.Println("No .env file found or error loading it.")
 	}
 
-	p := tea.NewProgram(initialModel())
+	out := &terminalOutput{File: os.Stdout}
+	p := tea.NewProgram(initialModel(out), tea.WithOutput(out))
 	if err := p.Start(); err != nil {
 		fmt.Printf("Error running program: %v\n", err)
 		os.Exit(1)
@@ -76,52 +82,35 @@ func (m model) Init() tea.Cmd {
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	switch m.state {
-	case "menu":
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.session.width = msg.Width
+		m.session.height = msg.Height
+	case clipboardClearMsg:
+		// Secrets are cleared even after leaving the screen they were copied from
+		m.session.clearClipboard(msg)
+		return m, nil
+	}
+	if m.screen == nil {
 		return m.updateMenu(msg)
-	case "convert":
-		return m.updateConvert(msg)
-	case "generate":
-		return m.updateGenerate()
-	case "farcaster":
-		return m.updateFarcaster(msg)
-	case "sign":
-		return m.updateSign(msg)
-	case "verify":
-		return m.updateVerify(msg)
-	case "display":
-		if keyMsg, ok := msg.(tea.KeyMsg); ok && keyMsg.String() == "enter" {
-			m.state = "menu"
-			m.content = ""
-			m.input = ""
-			m.input2 = ""
-			m.input3 = ""
-			m.step = 0
-			return m, nil
+	}
+	next, cmd := m.screen.Update(msg)
+	// Wipe the screen unless it stays open, even when a fresh screen of the
+	// same type takes over
+	if w, ok := m.screen.(wiper); ok {
+		if n, ok := next.(wiper); !ok || n.id() != w.id() {
+			w.wipe()
 		}
 	}
-	return m, nil
+	m.screen = next
+	return m, cmd
 }
 
 func (m model) View() string {
-	switch m.state {
-	case "menu":
+	if m.screen == nil {
 		return m.viewMenu()
-	case "convert":
-		return m.viewConvert()
-	case "generate":
-		return m.viewGenerate()
-	case "farcaster":
-		return m.viewFarcaster()
-	case "sign":
-		return m.viewSign()
-	case "verify":
-		return m.viewVerify()
-	case "display":
-		return m.viewDisplay()
-	default:
-		return "Unknown state"
 	}
+	return m.screen.View()
 }
 
 func (m model) updateMenu(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -130,45 +119,25 @@ func (m model) updateMenu(msg tea.Msg) (tea.Model, tea.Cmd) {
 		switch msg.String() {
 		case "ctrl+c", "q":
 			m.quitting = true
+			m.session.lock()
 			return m, tea.Quit
 		case "up", "k":
 			if m.cursor > 0 {
 				m.cursor--
 			}
 		case "down", "j":
-			if m.cursor < len(m.choices)-1 {
+			// The last entry is Quit
+			if m.cursor < len(tools) {
 				m.cursor++
 			}
 		case "enter", " ":
-			m.selected = m.choices[m.cursor]
-			switch m.cursor {
-			case 0:
-				m.state = "convert"
-				m.input = ""
-				m.content = ""
-			case 1:
-				m.state = "generate"
-			case 2:
-				m.state = "farcaster"
-				m.input = ""
-				m.content = ""
-			case 3:
-				m.state = "sign"
-				m.input = ""
-				m.input2 = ""
-				m.content = ""
-				m.step = 0
-			case 4:
-				m.state = "verify"
-				m.input = ""
-				m.input2 = ""
-				m.input3 = ""
-				m.content = ""
-				m.step = 0
-			case 5:
+			if m.cursor == len(tools) {
 				m.quitting = true
+				m.session.lock()
 				return m, tea.Quit
 			}
+			m.screen = tools[m.cursor].open(m.session)
+			return m, m.screen.Init()
 		}
 	}
 
@@ -178,7 +147,7 @@ func (m model) updateMenu(msg tea.Msg) (tea.Model, tea.Cmd) {
 func (m model) viewMenu() string {
 	s := titleStyle.Render("Ethereum Tools Menu") + "\n\n"
 
-	for i, choice := range m.choices {
+	for i, choice := range append(toolTitles(), "Quit") {
 		cursor := " "
 		if m.cursor == i {
 			cursor = ">"
@@ -191,399 +160,805 @@ func (m model) viewMenu() string {
 	return s
 }
 
-func (m model) updateConvert(msg tea.Msg) (tea.Model, tea.Cmd) {
+// toolTitles returns the menu titles of the registered tools.
+func toolTitles() []string {
+	titles := make([]string, len(tools))
+	for i, t := range tools {
+		titles[i] = t.title
+	}
+	return titles
+}
+
+// convertScreen derives the address of a private key.
+type convertScreen struct {
+	screenID
+	session *session
+	key     textInput
+	content string
+}
+
+func newConvertScreen(s *session) Screen {
+	return convertScreen{screenID: newScreenID(), session: s, key: newSecretInput()}
+}
+
+func (c convertScreen) Init() tea.Cmd {
+	return nil
+}
+
+func (c convertScreen) Update(msg tea.Msg) (Screen, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch msg.Type {
 		case tea.KeyCtrlC, tea.KeyEsc:
-			m.state = "menu"
+			return nil, nil
 		case tea.KeyEnter:
-			if len(m.input) == 0 {
-				m.content = "Error: Private key cannot be empty."
-				return m, nil
+			key := c.key.Secret()
+			defer key.Wipe()
+			if len(key) == 0 {
+				c.content = "Error: Private key cannot be empty."
+				return c, nil
 			}
 
-			privateKeyHex := strings.TrimSpace(m.input)
-			privateKey, err := crypto.HexToECDSA(privateKeyHex)
+			privateKey, err := key.PrivateKey()
 			if err != nil {
-				m.content = fmt.Sprintf("Error converting private key: %v", err)
-				return m, nil
+				c.content = fmt.Sprintf("Error converting private key: %v", err)
+				return c, nil
 			}
+			defer wipeKey(privateKey)
 
 			address := crypto.PubkeyToAddress(privateKey.PublicKey)
-			m.content = fmt.Sprintf("Ethereum Address: %s", address.Hex())
-			m.state = "display"
-			return m, nil
-		case tea.KeyRunes:
-			m.input += string(msg.Runes)
-		case tea.KeyBackspace, tea.KeyDelete:
-			if len(m.input) > 0 {
-				m.input = m.input[:len(m.input)-1]
-			}
+			return c.session.showResult(&AddressResult{Address: address.Hex()}), nil
+		default:
+			c.key = c.key.Update(msg)
 		}
 	}
-	return m, nil
+	return c, nil
 }
 
-func (m model) viewConvert() string {
+func (c convertScreen) wipe() {
+	c.key.Reset()
+}
+
+func (c convertScreen) View() string {
 	s := titleStyle.Render("Convert Private Key to Address") + "\n\n"
 	s += "Enter your Ethereum private key (in hex format) or press Esc to cancel:\n"
-	s += inputStyle.Render(m.input)
-	if m.content != "" {
-		s += "\n\n" + m.content + "\n\nPress Enter to continue..."
+	s += c.key.View()
+	if c.content != "" {
+		s += "\n\n" + c.content + "\n\nPress Enter to continue..."
 	}
 	return s
 }
 
-func (m model) updateGenerate() (tea.Model, tea.Cmd) {
+// generateKey creates a new private key and shows it once revealed.
+func generateKey(s *session) Screen {
 	privateKey, err := crypto.GenerateKey()
 	if err != nil {
-		m.content = fmt.Sprintf("Error generating private key: %v", err)
-	} else {
-		privateKeyBytes := crypto.FromECDSA(privateKey)
-		privateKeyHex := fmt.Sprintf("%x", privateKeyBytes)
-		address := crypto.PubkeyToAddress(privateKey.PublicKey)
-		m.content = fmt.Sprintf("New Private Key: %s\nCorresponding Ethereum Address: %s\n\nWARNING: Store this private key securely. Never share it with anyone!", privateKeyHex, address.Hex())
-	}
-	m.state = "display"
-	return m, nil
+		return s.showText(fmt.Sprintf("Error generating private key: %v", err))
+	}
+	defer wipeKey(privateKey)
+	address := crypto.PubkeyToAddress(privateKey.PublicKey)
+	return s.showResult(&KeyResult{PrivateKey: privateKeySecret(privateKey), Address: address.Hex()})
 }
 
-func (m model) viewGenerate() string {
-	s := titleStyle.Render("Generate New Private Key") + "\n\n"
-	if m.content != "" {
-		s += m.content + "\n\nPress Enter to continue..."
+// newAirstackClient returns an Airstack client configured from the
+// environment: $AIRSTACK_API_KEY and optionally $AIRSTACK_URL, another
+// GraphQL endpoint, and $AIRSTACK_TIMEOUT, the time allowed for each
+// attempt of a request as a duration such as 30s.
+func newAirstackClient() (*airstack.AirstackClient, error) {
+	apiKey := os.Getenv("AIRSTACK_API_KEY")
+	if apiKey == "" {
+		return nil, errors.New("AIRSTACK_API_KEY not set")
 	}
-	return s
+	client := airstack.NewClient()
+	client.SetAPIKey(apiKey)
+	if baseURL := os.Getenv("AIRSTACK_URL"); baseURL != "" {
+		client.SetBaseURL(baseURL)
+	}
+	if timeout := os.Getenv("AIRSTACK_TIMEOUT"); timeout != "" {
+		d, err := time.ParseDuration(timeout)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("invalid AIRSTACK_TIMEOUT %q", timeout)
+		}
+		client.Timeout = d
+	}
+	return client, nil
+}
+
+// farcasterLookupLimit is the number of casts and channels looked up for a
+// Farcaster account.
+const farcasterLookupLimit = 5
+
+// farcasterLookupTimeout bounds a lookup from the command line, retries
+// included.
+const farcasterLookupTimeout = time.Minute
+
+// farcasterProviders are the values of $FARCASTER_PROVIDER, with the
+// variable holding the API key of each provider.
+var farcasterProviders = map[string]struct {
+	apiKeyVar string
+	open      func() (farcaster.Provider, error)
+}{
+	"airstack": {"AIRSTACK_API_KEY", newAirstackProvider},
+	"neynar":   {"NEYNAR_API_KEY", newNeynarProvider},
+	"hub":      {"FARCASTER_HUB_API_KEY", newHubProvider},
+}
+
+// farcasterProviderName returns the provider chosen by $FARCASTER_PROVIDER,
+// airstack by default.
+func farcasterProviderName() string {
+	if name := strings.ToLower(strings.TrimSpace(os.Getenv("FARCASTER_PROVIDER"))); name != "" {
+		return name
+	}
+	return "airstack"
+}
+
+// newFarcasterProvider returns the Farcaster data provider chosen by
+// $FARCASTER_PROVIDER, configured from the environment.
+func newFarcasterProvider() (farcaster.Provider, error) {
+	name := farcasterProviderName()
+	provider, ok := farcasterProviders[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown FARCASTER_PROVIDER %q (use airstack, neynar or hub)", name)
+	}
+	return provider.open()
+}
+
+func newAirstackProvider() (farcaster.Provider, error) {
+	client, err := newAirstackClient()
+	if err != nil {
+		return nil, err
+	}
+	return farcaster.NewAirstack(client), nil
+}
+
+// newNeynarProvider returns a Neynar provider configured from
+// $NEYNAR_API_KEY and optionally $NEYNAR_URL, another API endpoint.
+func newNeynarProvider() (farcaster.Provider, error) {
+	apiKey := os.Getenv("NEYNAR_API_KEY")
+	if apiKey == "" {
+		return nil, errors.New("NEYNAR_API_KEY not set")
+	}
+	provider := farcaster.NewNeynar(apiKey)
+	if baseURL := os.Getenv("NEYNAR_URL"); baseURL != "" {
+		provider.BaseURL = baseURL
+	}
+	return provider, nil
+}
+
+// newHubProvider returns a hub provider configured from $FARCASTER_HUB_URL
+// and optionally $FARCASTER_HUB_API_KEY, for hosted hubs that require one.
+func newHubProvider() (farcaster.Provider, error) {
+	baseURL := os.Getenv("FARCASTER_HUB_URL")
+	if baseURL == "" {
+		return nil, errors.New("FARCASTER_HUB_URL not set")
+	}
+	provider := farcaster.NewHub(baseURL)
+	if apiKey := os.Getenv("FARCASTER_HUB_API_KEY"); apiKey != "" {
+		provider.SetAPIKey(apiKey)
+	}
+	return provider, nil
+}
+
+// describeFarcasterError explains a failed Farcaster lookup, with a hint on
+// how to fix the usual causes.
+func describeFarcasterError(err error) string {
+	switch {
+	case errors.Is(err, farcaster.ErrUnauthorized):
+		return fmt.Sprintf("%v (check %s)", err, farcasterProviders[farcasterProviderName()].apiKeyVar)
+	case errors.Is(err, farcaster.ErrRateLimited):
+		return fmt.Sprintf("%v (try again in a minute)", err)
+	case errors.Is(err, farcaster.ErrSchema):
+		return fmt.Sprintf("%v (the API of the provider has changed; please report this)", err)
+	}
+	return err.Error()
+}
+
+// farcasterScreen looks up a Farcaster account. lookup is set while a
+// request is in flight and can be cancelled with Esc.
+type farcasterScreen struct {
+	session  *session
+	username textInput
+	lookup   *farcasterLookup
+	content  string
+}
+
+// farcasterLookup is a running Farcaster account request.
+type farcasterLookup struct {
+	cancel context.CancelFunc
+}
+
+// farcasterResultMsg carries the outcome of a Farcaster account request,
+// either a result or a message to show instead.
+type farcasterResultMsg struct {
+	lookup  *farcasterLookup
+	result  Result
+	message string
 }
 
-func (m model) updateFarcaster(msg tea.Msg) (tea.Model, tea.Cmd) {
+func newFarcasterScreen(s *session) Screen {
+	return farcasterScreen{session: s, username: newTextInput()}
+}
+
+func (f farcasterScreen) Init() tea.Cmd {
+	return nil
+}
+
+func (f farcasterScreen) Update(msg tea.Msg) (Screen, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch msg.Type {
 		case tea.KeyCtrlC, tea.KeyEsc:
-			m.state = "menu"
+			if f.lookup != nil {
+				// Cancel the request but stay on the screen
+				f.lookup.cancel()
+				f.lookup = nil
+				f.content = "Request cancelled."
+				return f, nil
+			}
+			return nil, nil
 		case tea.KeyEnter:
-			if len(m.input) == 0 {
-				m.content = "Error: Farcaster username cannot be empty."
-				return m, nil
+			if f.lookup != nil {
+				return f, nil
+			}
+			fname := strings.TrimSpace(f.username.Value())
+			if fname == "" {
+				f.content = "Error: Farcaster username cannot be empty."
+				return f, nil
+			}
+			provider, err := newFarcasterProvider()
+			if err != nil {
+				f.content = fmt.Sprintf("Error: %v.", err)
+				return f, nil
 			}
 
 			// Start fetching data
-			m.content = "Waiting for answer..."
-			// Store the input locally to avoid race conditions
-			input := m.input
-			return m, func() tea.Msg {
-				// Perform API call here
-				client := airstack.NewClient()
-				apiKey := os.Getenv("AIRSTACK_API_KEY")
-				if apiKey == "" {
-					return "Error: AIRSTACK_API_KEY not set."
+			f.content = "Waiting for answer... press Esc to cancel."
+			ctx, cancel := context.WithCancel(context.Background())
+			lookup := &farcasterLookup{cancel: cancel}
+			f.lookup = lookup
+			return f, func() tea.Msg {
+				defer cancel()
+				account, err := farcaster.Lookup(ctx, provider, fname, farcasterLookupLimit)
+				if errors.Is(err, farcaster.ErrNotFound) {
+					return farcasterResultMsg{lookup: lookup, message: "No data found for the provided Farcaster username."}
 				}
-				client.SetAPIKey(apiKey)
-
-				fname := strings.TrimSpace(input)
-
-				result, err := client.QueryFarcasterAccount(fname)
 				if err != nil {
-					return fmt.Sprintf("Error querying Airstack API: %v", err)
-				}
-
-				// Format the results
-				if len(result.Data.Socials.Social) == 0 && len(result.Data.FarcasterCasts.Cast) == 0 {
-					return "No data found for the provided Farcaster username."
+					return farcasterResultMsg{lookup: lookup, message: fmt.Sprintf("Error querying %s: %s", provider.Name(), describeFarcasterError(err))}
 				}
 
-				output := formatFarcasterData(fname, result)
-				return output
+				return farcasterResultMsg{lookup: lookup, result: NewFarcasterResult(fname, provider.Name(), account)}
 			}
-		case tea.KeyRunes:
-			m.input += string(msg.Runes)
-		case tea.KeyBackspace, tea.KeyDelete:
-			if len(m.input) > 0 {
-				m.input = m.input[:len(m.input)-1]
+		default:
+			if f.lookup == nil {
+				f.username = f.username.Update(msg)
 			}
 		}
-	case string:
-		m.content = msg
-		m.state = "display"
-		return m, nil
+	case farcasterResultMsg:
+		if f.lookup == nil || f.lookup != msg.lookup {
+			// Answer to a request that was already cancelled
+			return f, nil
+		}
+		f.lookup = nil
+		if msg.result == nil {
+			return f.session.showText(msg.message), nil
+		}
+		return f.session.showResult(msg.result), nil
 	}
 
-	return m, nil
+	return f, nil
 }
 
-func (m model) viewFarcaster() string {
+func (f farcasterScreen) View() string {
 	s := titleStyle.Render("Check Farcaster Account") + "\n\n"
 	s += "Enter Farcaster username or press Esc to cancel:\n"
-	s += inputStyle.Render(m.input)
-	if m.content != "" {
-		s += "\n\n" + m.content + "\n\nPress Enter to continue..."
+	s += f.username.View()
+	if f.lookup != nil {
+		s += "\n\n" + f.content
+	} else if f.content != "" {
+		s += "\n\n" + f.content + "\n\nPress Enter to continue..."
 	}
 	return s
 }
 
-func (m model) updateSign(msg tea.Msg) (tea.Model, tea.Cmd) {
+// signScreen signs a message with a private key.
+type signScreen struct {
+	screenID
+	session *session
+	scheme  SignatureScheme
+	step    int
+	key     textInput
+	message textInput
+	content string
+}
+
+func newSignScreen(s *session) Screen {
+	return signScreen{screenID: newScreenID(), session: s, key: newSecretInput(), message: newMultilineInput()}
+}
+
+func (sc signScreen) Init() tea.Cmd {
+	return nil
+}
+
+func (sc signScreen) Update(msg tea.Msg) (Screen, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch msg.Type {
 		case tea.KeyCtrlC, tea.KeyEsc:
-			m.input = ""
-			m.input2 = ""
-			m.content = ""
-			m.step = 0
-			m.state = "menu"
+			return nil, nil
+		case tea.KeyTab:
+			sc.scheme = sc.scheme.next()
 		case tea.KeyEnter:
-			if m.step == 0 {
-				if len(m.input) == 0 {
-					m.content = "Error: Private key cannot be empty."
-					return m, nil
-				}
+			key := sc.key.Secret()
+			defer key.Wipe()
+			if sc.step == 0 {
 				// Validate private key
-				_, err := crypto.HexToECDSA(strings.TrimSpace(m.input))
+				privateKey, err := sc.session.signingKey(key)
 				if err != nil {
-					m.content = "Error: Invalid private key format."
-					return m, nil
+					sc.content = signingKeyError(err)
+					return sc, nil
 				}
-				m.step = 1
-			} else if m.step == 1 {
-				if len(m.input2) == 0 {
-					m.content = "Error: Message cannot be empty."
-					return m, nil
+				wipeKey(privateKey)
+				sc.step = 1
+			} else if sc.step == 1 {
+				if sc.message.Value() == "" {
+					sc.content = "Error: Message cannot be empty."
+					return sc, nil
 				}
 				// Sign the message
-				privateKeyHex := strings.TrimSpace(m.input)
-				message := m.input2
-				signature, err := SignMessage(privateKeyHex, message)
+				privateKey, err := sc.session.signingKey(key)
 				if err != nil {
-					m.content = fmt.Sprintf("Error signing message: %v", err)
-					return m, nil
+					sc.content = signingKeyError(err)
+					return sc, nil
 				}
-				m.content = fmt.Sprintf("Signature:\n%s", signature)
-				m.state = "display"
-				return m, nil
-			}
-		case tea.KeyRunes:
-			if m.step == 0 {
-				m.input += string(msg.Runes)
-			} else if m.step == 1 {
-				m.input2 += string(msg.Runes)
+				defer wipeKey(privateKey)
+				message := sc.message.Value()
+				signature, err := signMessageWithKey(privateKey, message, sc.scheme)
+				if err != nil {
+					sc.content = fmt.Sprintf("Error signing message: %v", err)
+					return sc, nil
+				}
+				return sc.session.showResult(&SignatureResult{Scheme: sc.scheme.name(), Signature: signature}), nil
 			}
-		case tea.KeyBackspace, tea.KeyDelete:
-			if m.step == 0 && len(m.input) > 0 {
-				m.input = m.input[:len(m.input)-1]
-			} else if m.step == 1 && len(m.input2) > 0 {
-				m.input2 = m.input2[:len(m.input2)-1]
+		default:
+			if sc.step == 0 {
+				sc.key = sc.key.Update(msg)
+			} else {
+				sc.message = sc.message.Update(msg)
 			}
 		}
 	}
-	return m, nil
+	return sc, nil
 }
 
-func (m model) viewSign() string {
+func (sc signScreen) wipe() {
+	sc.key.Reset()
+}
+
+func (sc signScreen) View() string {
 	s := titleStyle.Render("Sign Message with Private Key") + "\n\n"
-	if m.step == 0 {
+	s += fmt.Sprintf("Scheme: %s (press Tab to switch)\n\n", menuStyle.Render(sc.scheme.String()))
+	if sc.step == 0 {
 		s += "Enter your Ethereum private key (in hex format) or press Esc to cancel:\n"
-		s += inputStyle.Render(m.input)
-		if m.content != "" {
-			s += "\n\n" + m.content + "\n\nPress Enter to continue..."
-		}
-	} else if m.step == 1 {
+		s += sc.session.unlockedHint()
+		s += sc.key.View()
+	} else if sc.step == 1 {
 		s += "Enter the message you wish to sign or press Esc to cancel:\n"
-		s += inputStyle.Render(m.input2)
-		if m.content != "" {
-			s += "\n\n" + m.content + "\n\nPress Enter to continue..."
-		}
+		s += sc.message.View()
+	}
+	if sc.content != "" {
+		s += "\n\n" + sc.content + "\n\nPress Enter to continue..."
 	}
 	return s
 }
 
-func (m model) updateVerify(msg tea.Msg) (tea.Model, tea.Cmd) {
+// verifyScreen checks a message signature against the signer's address.
+type verifyScreen struct {
+	session   *session
+	scheme    SignatureScheme
+	step      int
+	message   textInput
+	signature textInput
+	address   textInput
+	check     *verifyCheck
+	content   string
+}
+
+// verifyCheck is a running on-chain signature check.
+type verifyCheck struct {
+	cancel context.CancelFunc
+}
+
+// verifyResultMsg carries the outcome of an on-chain signature check,
+// either a result or a message to show instead.
+type verifyResultMsg struct {
+	check   *verifyCheck
+	result  Result
+	message string
+}
+
+func newVerifyScreen(s *session) Screen {
+	return verifyScreen{session: s, message: newMultilineInput(), signature: newTextInput(), address: newTextInput()}
+}
+
+func (v verifyScreen) Init() tea.Cmd {
+	return nil
+}
+
+func (v verifyScreen) Update(msg tea.Msg) (Screen, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch msg.Type {
 		case tea.KeyCtrlC, tea.KeyEsc:
-			m.input = ""
-			m.input2 = ""
-			m.input3 = ""
-			m.content = ""
-			m.step = 0
-			m.state = "menu"
+			if v.check != nil {
+				// Cancel the check but stay on the screen
+				v.check.cancel()
+				v.check = nil
+				v.content = "Verification cancelled."
+				return v, nil
+			}
+			return nil, nil
+		case tea.KeyTab:
+			if v.check == nil {
+				v.scheme = v.scheme.next()
+			}
 		case tea.KeyEnter:
-			if m.step == 0 {
-				if len(m.input) == 0 {
-					m.content = "Error: Message cannot be empty."
-					return m, nil
+			if v.check != nil {
+				return v, nil
+			}
+			if v.step == 0 {
+				if v.message.Value() == "" {
+					v.content = "Error: Message cannot be empty."
+					return v, nil
 				}
-				m.step = 1
-			} else if m.step == 1 {
-				if len(m.input2) == 0 {
-					m.content = "Error: Signature cannot be empty."
-					return m, nil
+				v.step = 1
+			} else if v.step == 1 {
+				if v.signature.Value() == "" {
+					v.content = "Error: Signature cannot be empty."
+					return v, nil
 				}
-				m.step = 2
-			} else if m.step == 2 {
-				if len(m.input3) == 0 {
-					m.content = "Error: Ethereum address cannot be empty."
-					return m, nil
+				v.step = 2
+			} else if v.step == 2 {
+				if v.address.Value() == "" {
+					v.content = "Error: Ethereum address cannot be empty."
+					return v, nil
 				}
 				// Verify the signature
-				message := m.input
-				signature := m.input2
-				address := m.input3
-				valid, err := VerifySignature(message, signature, address)
-				if err != nil {
-					m.content = fmt.Sprintf("Error verifying signature: %v", err)
-					return m, nil
+				message := v.message.Value()
+				signature := v.signature.Value()
+				address := v.address.Value()
+
+				// With an RPC endpoint, smart contract accounts can be checked too
+				if rpcURL := os.Getenv("ETH_RPC_URL"); rpcURL != "" {
+					v.content = "Verifying on-chain... press Esc to cancel."
+					scheme := v.scheme
+					ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+					check := &verifyCheck{cancel: cancel}
+					v.check = check
+					return v, func() tea.Msg {
+						defer cancel()
+						valid, signerType, err := VerifySignatureOnChain(ctx, rpcURL, message, signature, address, scheme)
+						if err != nil {
+							return verifyResultMsg{check: check, message: fmt.Sprintf("Error verifying signature: %v", err)}
+						}
+						return verifyResultMsg{check: check, result: &VerificationResult{Valid: valid, Address: address, Scheme: scheme.name(), SignerType: signerType.String()}}
+					}
 				}
-				if valid {
-					m.content = "Signature is valid."
-				} else {
-					m.content = "Signature is invalid."
+
+				valid, err := VerifySignature(message, signature, address, v.scheme)
+				if err != nil {
+					v.content = fmt.Sprintf("Error verifying signature: %v", err)
+					return v, nil
 				}
-				m.state = "display"
-				return m, nil
+				return v.session.showResult(&VerificationResult{Valid: valid, Address: address, Scheme: v.scheme.name()}), nil
 			}
-		case tea.KeyRunes:
-			if m.step == 0 {
-				m.input += string(msg.Runes)
-			} else if m.step == 1 {
-				m.input2 += string(msg.Runes)
-			} else if m.step == 2 {
-				m.input3 += string(msg.Runes)
+		default:
+			if v.check != nil {
+				return v, nil
 			}
-		case tea.KeyBackspace, tea.KeyDelete:
-			if m.step == 0 && len(m.input) > 0 {
-				m.input = m.input[:len(m.input)-1]
-			} else if m.step == 1 && len(m.input2) > 0 {
-				m.input2 = m.input2[:len(m.input2)-1]
-			} else if m.step == 2 && len(m.input3) > 0 {
-				m.input3 = m.input3[:len(m.input3)-1]
+			switch v.step {
+			case 0:
+				v.message = v.message.Update(msg)
+			case 1:
+				v.signature = v.signature.Update(msg)
+			case 2:
+				v.address = v.address.Update(msg)
 			}
 		}
+	case verifyResultMsg:
+		if v.check == nil || v.check != msg.check {
+			// Answer to a check that was already cancelled
+			return v, nil
+		}
+		v.check = nil
+		if msg.result == nil {
+			return v.session.showText(msg.message), nil
+		}
+		return v.session.showResult(msg.result), nil
 	}
-	return m, nil
+	return v, nil
 }
 
-func (m model) viewVerify() string {
+func (v verifyScreen) View() string {
 	s := titleStyle.Render("Verify Signature") + "\n\n"
-	if m.step == 0 {
+	s += fmt.Sprintf("Scheme: %s (press Tab to switch)\n\n", menuStyle.Render(v.scheme.String()))
+	if v.step == 0 {
 		s += "Enter the message that was signed or press Esc to cancel:\n"
-		s += inputStyle.Render(m.input)
-		if m.content != "" {
-			s += "\n\n" + m.content + "\n\nPress Enter to continue..."
-		}
-	} else if m.step == 1 {
+		s += v.message.View()
+	} else if v.step == 1 {
 		s += "Enter the signature (in hex format) or press Esc to cancel:\n"
-		s += inputStyle.Render(m.input2)
-		if m.content != "" {
-			s += "\n\n" + m.content + "\n\nPress Enter to continue..."
-		}
-	} else if m.step == 2 {
+		s += v.signature.View()
+	} else if v.step == 2 {
 		s += "Enter the Ethereum address of the signer or press Esc to cancel:\n"
-		s += inputStyle.Render(m.input3)
-		if m.content != "" {
-			s += "\n\n" + m.content + "\n\nPress Enter to continue..."
+		s += v.address.View()
+	}
+	if v.check != nil {
+		s += "\n\n" + v.content
+	} else if v.content != "" {
+		s += "\n\n" + v.content + "\n\nPress Enter to continue..."
+	}
+	return s
+}
+
+// recoverScreen recovers the signer of a message from its signature.
+type recoverScreen struct {
+	session   *session
+	scheme    SignatureScheme
+	step      int
+	message   textInput
+	signature textInput
+	content   string
+}
+
+func newRecoverScreen(s *session) Screen {
+	return recoverScreen{session: s, message: newMultilineInput(), signature: newTextInput()}
+}
+
+func (r recoverScreen) Init() tea.Cmd {
+	return nil
+}
+
+func (r recoverScreen) Update(msg tea.Msg) (Screen, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.Type {
+		case tea.KeyCtrlC, tea.KeyEsc:
+			return nil, nil
+		case tea.KeyTab:
+			r.scheme = r.scheme.next()
+		case tea.KeyEnter:
+			if r.step == 0 {
+				if r.message.Value() == "" {
+					r.content = "Error: Message cannot be empty."
+					return r, nil
+				}
+				r.step = 1
+			} else if r.step == 1 {
+				if r.signature.Value() == "" {
+					r.content = "Error: Signature cannot be empty."
+					return r, nil
+				}
+				// Recover the signer
+				signer, err := RecoverSigner(r.message.Value(), strings.TrimSpace(r.signature.Value()), r.scheme)
+				if err != nil {
+					r.content = fmt.Sprintf("Error recovering signer: %v", err)
+					return r, nil
+				}
+				return r.session.showResult(NewRecoveredSignerResult(signer)), nil
+			}
+		default:
+			if r.step == 0 {
+				r.message = r.message.Update(msg)
+			} else {
+				r.signature = r.signature.Update(msg)
+			}
 		}
 	}
+	return r, nil
+}
+
+func (r recoverScreen) View() string {
+	s := titleStyle.Render("Recover Signer") + "\n\n"
+	s += fmt.Sprintf("Scheme: %s (press Tab to switch)\n\n", menuStyle.Render(r.scheme.String()))
+	if r.step == 0 {
+		s += "Enter the message that was signed or press Esc to cancel:\n"
+		s += r.message.View()
+	} else if r.step == 1 {
+		s += "Enter the signature (in hex format) or press Esc to cancel:\n"
+		s += r.signature.View()
+	}
+	if r.content != "" {
+		s += "\n\n" + r.content + "\n\nPress Enter to continue..."
+	}
 	return s
 }
 
-func SignMessage(privateKeyHex, message string) (string, error) {
+// SignatureScheme selects how a message is hashed before it is signed or
+// verified.
+type SignatureScheme int
+
+const (
+	// SchemeEIP191 is the EIP-191 personal_sign scheme used by wallets and
+	// contracts: keccak256("\x19Ethereum Signed Message:\n" + len + message),
+	// with v encoded as 27/28.
+	SchemeEIP191 SignatureScheme = iota
+	// SchemeLegacySHA256 is the scheme used by earlier versions of this tool:
+	// sha256(message) signed directly, with v encoded as 0/1. It is kept so
+	// old signatures still verify and is not understood by other software.
+	SchemeLegacySHA256
+)
+
+// String returns a human readable name for the scheme.
+func (s SignatureScheme) String() string {
+	switch s {
+	case SchemeEIP191:
+		return "EIP-191 personal_sign"
+	case SchemeLegacySHA256:
+		return "Legacy SHA-256"
+	default:
+		return fmt.Sprintf("SignatureScheme(%d)", int(s))
+	}
+}
+
+// next returns the scheme that follows s when cycling through them in the UI.
+func (s SignatureScheme) next() SignatureScheme {
+	if s == SchemeEIP191 {
+		return SchemeLegacySHA256
+	}
+	return SchemeEIP191
+}
+
+// hashMessage returns the digest that is signed for message under scheme.
+func hashMessage(message string, scheme SignatureScheme) ([]byte, error) {
+	switch scheme {
+	case SchemeEIP191:
+		return accounts.TextHash([]byte(message)), nil
+	case SchemeLegacySHA256:
+		msgHash := sha256.Sum256([]byte(message))
+		return msgHash[:], nil
+	default:
+		return nil, fmt.Errorf("unknown signature scheme: %v", scheme)
+	}
+}
+
+func SignMessage(privateKeyHex, message string, scheme SignatureScheme) (string, error) {
 	// Convert private key from hex string to ECDSA private key
 	privateKey, err := crypto.HexToECDSA(privateKeyHex)
 	if err != nil {
 		return "", fmt.Errorf("invalid private key: %v", err)
 	}
+	return signMessageWithKey(privateKey, message, scheme)
+}
 
+// signMessageWithKey signs message under scheme with an already parsed
+// private key.
+func signMessageWithKey(privateKey *ecdsa.PrivateKey, message string, scheme SignatureScheme) (string, error) {
 	// Hash the message
-	msgHash := sha256.Sum256([]byte(message))
+	msgHash, err := hashMessage(message, scheme)
+	if err != nil {
+		return "", err
+	}
 
 	// Sign the hash
-	signatureBytes, err := crypto.Sign(msgHash[:], privateKey)
+	signatureBytes, err := crypto.Sign(msgHash, privateKey)
 	if err != nil {
 		return "", fmt.Errorf("failed to sign message: %v", err)
 	}
 
+	// EIP-191 signatures carry v as 27/28
+	if scheme == SchemeEIP191 {
+		signatureBytes[crypto.RecoveryIDOffset] += 27
+	}
+
 	// Return the hex-encoded signature
 	signatureHex := hexutil.Encode(signatureBytes)
 	return signatureHex, nil
 }
 
-func VerifySignature(message, signatureHex, addressHex string) (bool, error) {
+func VerifySignature(message, signatureHex, addressHex string, scheme SignatureScheme) (bool, error) {
 	// Validate the address
 	if !common.IsHexAddress(addressHex) {
 		return false, fmt.Errorf("invalid Ethereum address")
 	}
 	address := common.HexToAddress(addressHex)
 
-	// Decode the signature
-	signatureBytes, err := hexutil.Decode(signatureHex)
+	signer, err := RecoverSigner(message, signatureHex, scheme)
 	if err != nil {
-		return false, fmt.Errorf("invalid signature format")
+		return false, err
 	}
-	if len(signatureBytes) != 65 {
-		return false, fmt.Errorf("invalid signature length")
+
+	// Compare recovered address with provided address
+	if signer.Address == address {
+		return true, nil
 	}
+	return false, nil
+}
 
-	// Hash the message
-	msgHash := sha256.Sum256([]byte(message))
+// RecoveredSigner describes the key that produced a signature.
+type RecoveredSigner struct {
+	Address             common.Address
+	PublicKey           []byte // uncompressed, 65 bytes
+	CompressedPublicKey []byte // compressed, 33 bytes
+	RecoveryID          byte   // 0 or 1
+}
 
-	// Remove recovery ID (last byte)
-	sigPublicKey, err := crypto.Ecrecover(msgHash[:], signatureBytes)
+// RecoverSigner recovers the address and public key that signed message
+// under scheme, without needing to know the signer in advance.
+func RecoverSigner(message, signatureHex string, scheme SignatureScheme) (*RecoveredSigner, error) {
+	// Decode the signature in any supported format into the 0/1 recovery form
+	signatureBytes, err := decodeSignature(signatureHex)
 	if err != nil {
-		return false, fmt.Errorf("failed to recover public key: %v", err)
+		return nil, err
 	}
 
-	// Convert to ECDSA public key
-	pubKey, err := crypto.UnmarshalPubkey(sigPublicKey)
+	// Hash the message
+	msgHash, err := hashMessage(message, scheme)
 	if err != nil {
-		return false, fmt.Errorf("failed to unmarshal public key: %v", err)
+		return nil, err
 	}
 
-	// Generate address from public key
-	recoveredAddr := crypto.PubkeyToAddress(*pubKey)
-
-	// Compare recovered address with provided address
-	if recoveredAddr == address {
-		return true, nil
+	pubKey, err := crypto.SigToPub(msgHash, signatureBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to recover public key: %v", err)
 	}
-	return false, nil
+
+	return &RecoveredSigner{
+		Address:             crypto.PubkeyToAddress(*pubKey),
+		PublicKey:           crypto.FromECDSAPub(pubKey),
+		CompressedPublicKey: crypto.CompressPubkey(pubKey),
+		RecoveryID:          signatureBytes[crypto.RecoveryIDOffset],
+	}, nil
 }
 
-func (m model) viewDisplay() string {
-	s := m.content + "\n\nPress Enter to return to menu..."
-	return s
+// RecoveredSignerResult is the signer recovered from a signature.
+type RecoveredSignerResult struct {
+	Address             string `json:"address" yaml:"address"`
+	RecoveryID          byte   `json:"recoveryId" yaml:"recoveryId"`
+	CompressedPublicKey string `json:"compressedPublicKey" yaml:"compressedPublicKey"`
+	PublicKey           string `json:"publicKey" yaml:"publicKey"`
 }
 
-func formatFarcasterData(fname string, result *airstack.FarcasterResponse) string {
-	var sb strings.Builder
+// NewRecoveredSignerResult converts a recovered signer.
+func NewRecoveredSignerResult(signer *RecoveredSigner) *RecoveredSignerResult {
+	return &RecoveredSignerResult{
+		Address:             signer.Address.Hex(),
+		RecoveryID:          signer.RecoveryID,
+		CompressedPublicKey: hexutil.Encode(signer.CompressedPublicKey),
+		PublicKey:           hexutil.Encode(signer.PublicKey),
+	}
+}
 
-	social := result.Data.Socials.Social
-	casts := result.Data.FarcasterCasts.Cast
+// Text implements Result.
+func (r *RecoveredSignerResult) Text() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Signer Address           : %s\n", r.Address))
+	sb.WriteString(fmt.Sprintf("Recovery ID              : %d\n", r.RecoveryID))
+	sb.WriteString(fmt.Sprintf("Public Key (compressed)  : %s\n", r.CompressedPublicKey))
+	sb.WriteString(fmt.Sprintf("Public Key (uncompressed): %s\n", r.PublicKey))
+	return sb.String()
+}
 
-	sb.WriteString(fmt.Sprintf("Results for Farcaster user '%s':\n\n", fname))
+// CopyFields implements Copyable.
+func (r *RecoveredSignerResult) CopyFields() []CopyField {
+	return []CopyField{
+		{Label: "Address", Value: r.Address},
+		{Label: "Public Key", Value: r.CompressedPublicKey},
+	}
+}
 
-	if len(social) > 0 {
-		s := social[0]
-		sb.WriteString("Profile Information:\n")
-		sb.WriteString(fmt.Sprintf("Profile Name   : %s\n", s.ProfileName))
-		sb.WriteString(fmt.Sprintf("Follower Count : %d\n", s.FollowerCount))
-		sb.WriteString(fmt.Sprintf("Following Count: %d\n", s.FollowingCount))
-		sb.WriteString(fmt.Sprintf("FarScore       : %.2f\n", s.FarcasterScore.FarScore))
-		sb.WriteString("\n")
-	} else {
-		sb.WriteString("No profile information found.\n\n")
+// recoverAddress returns the address that produced the 65-byte signature
+// (with a 0/1 recovery ID) over digest.
+func recoverAddress(digest, signature []byte) (common.Address, error) {
+	sigPublicKey, err := crypto.Ecrecover(digest, signature)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to recover public key: %v", err)
 	}
 
-	if len(casts) > 0 {
-		sb.WriteString("Recent Casts:\n")
-		for i, cast := range casts {
-			sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, cast.Text))
-		}
-	} else {
-		sb.WriteString("No recent casts found.\n")
+	// Convert to ECDSA public key
+	pubKey, err := crypto.UnmarshalPubkey(sigPublicKey)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to unmarshal public key: %v", err)
 	}
 
-	return sb.String()
+	// Generate address from public key
+	return crypto.PubkeyToAddress(*pubKey), nil
 }
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bytes"
 	"os"
 	"testing"
 
@@ -82,7 +83,7 @@ func TestSignMessage(t *testing.T) {
 	message := "This is a test message"
 
 	// Use the exported SignMessage function from app.go
-	signature, err := SignMessage(privateKeyHex, message)
+	signature, err := SignMessage(privateKeyHex, message, SchemeEIP191)
 	if err != nil {
 		t.Fatalf("Failed to sign message: %v", err)
 	}
@@ -110,13 +111,13 @@ func TestVerifySignature(t *testing.T) {
 	message := "This is a test message"
 
 	// Use the exported SignMessage function to create a signature
-	signature, err := SignMessage(privateKeyHex, message)
+	signature, err := SignMessage(privateKeyHex, message, SchemeEIP191)
 	if err != nil {
 		t.Fatalf("Failed to sign message: %v", err)
 	}
 
 	// Use the exported VerifySignature function to verify the signature
-	valid, err := VerifySignature(message, signature, expectedAddress)
+	valid, err := VerifySignature(message, signature, expectedAddress, SchemeEIP191)
 	if err != nil {
 		t.Fatalf("Failed to verify signature: %v", err)
 	}
@@ -125,3 +126,82 @@ func TestVerifySignature(t *testing.T) {
 		t.Fatalf("Signature verification failed")
 	}
 }
+
+func TestSignMessageEIP191(t *testing.T) {
+	// Reference vector from the web3.js documentation for eth.accounts.sign
+	privateKeyHex := "4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+	address := "0x2c7536E3605D9C16a7a3D7b1898e529396a65c23"
+	expected := "0xb91467e570a6466aa9e9876cbcd013baba02900b8979d43fe208a4a4f339f5fd6007e74cd82e037b800186422fc2da167c747ef045e5d18a5f5d4300f8e1a0291c"
+
+	signature, err := SignMessage(privateKeyHex, "Some data", SchemeEIP191)
+	if err != nil {
+		t.Fatalf("Failed to sign message: %v", err)
+	}
+	if signature != expected {
+		t.Fatalf("Signature mismatch. Expected %s, got %s", expected, signature)
+	}
+
+	valid, err := VerifySignature("Some data", signature, address, SchemeEIP191)
+	if err != nil {
+		t.Fatalf("Failed to verify signature: %v", err)
+	}
+	if !valid {
+		t.Fatal("EIP-191 signature verification failed")
+	}
+
+	// The same signature must not verify under the legacy scheme
+	valid, err = VerifySignature("Some data", signature, address, SchemeLegacySHA256)
+	if err == nil && valid {
+		t.Fatal("EIP-191 signature unexpectedly verified under the legacy scheme")
+	}
+}
+
+func TestLegacySHA256Signature(t *testing.T) {
+	privateKeyHex := "4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+	address := "0x2c7536E3605D9C16a7a3D7b1898e529396a65c23"
+
+	signature, err := SignMessage(privateKeyHex, "Some data", SchemeLegacySHA256)
+	if err != nil {
+		t.Fatalf("Failed to sign message: %v", err)
+	}
+
+	valid, err := VerifySignature("Some data", signature, address, SchemeLegacySHA256)
+	if err != nil {
+		t.Fatalf("Failed to verify signature: %v", err)
+	}
+	if !valid {
+		t.Fatal("Legacy signature verification failed")
+	}
+}
+
+func TestRecoverSigner(t *testing.T) {
+	privateKeyHex := "4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+	privateKey, err := crypto.HexToECDSA(privateKeyHex)
+	if err != nil {
+		t.Fatalf("Failed to convert private key to ECDSA: %v", err)
+	}
+
+	for _, scheme := range []SignatureScheme{SchemeEIP191, SchemeLegacySHA256} {
+		signature, err := SignMessage(privateKeyHex, "Some data", scheme)
+		if err != nil {
+			t.Fatalf("Failed to sign message: %v", err)
+		}
+
+		signer, err := RecoverSigner("Some data", signature, scheme)
+		if err != nil {
+			t.Fatalf("Failed to recover signer: %v", err)
+		}
+		if signer.Address != crypto.PubkeyToAddress(privateKey.PublicKey) {
+			t.Errorf("%v: recovered address mismatch, got %s", scheme, signer.Address.Hex())
+		}
+		if !bytes.Equal(signer.PublicKey, crypto.FromECDSAPub(&privateKey.PublicKey)) {
+			t.Errorf("%v: uncompressed public key mismatch", scheme)
+		}
+		if !bytes.Equal(signer.CompressedPublicKey, crypto.CompressPubkey(&privateKey.PublicKey)) {
+			t.Errorf("%v: compressed public key mismatch", scheme)
+		}
+		if signer.RecoveryID > 1 {
+			t.Errorf("%v: recovery ID out of range: %d", scheme, signer.RecoveryID)
+		}
+	}
+}
@@ -0,0 +1,547 @@
+// batch.go
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// BatchFormat is the file format of batch input and output.
+type BatchFormat int
+
+const (
+	// BatchCSV is comma separated values with a header row.
+	BatchCSV BatchFormat = iota
+	// BatchJSONL is one JSON object per line.
+	BatchJSONL
+)
+
+// BatchFormatFromPath picks the batch format from a file extension.
+func BatchFormatFromPath(path string) (BatchFormat, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return BatchCSV, nil
+	case ".jsonl", ".ndjson":
+		return BatchJSONL, nil
+	case ".json":
+		// A JSON array would fail line by line with confusing errors
+		return BatchCSV, fmt.Errorf("%q is a JSON file: batch files must be JSON Lines, one object per line, named .jsonl", path)
+	default:
+		return BatchCSV, fmt.Errorf("cannot tell the format of %q: use a .csv or .jsonl file", path)
+	}
+}
+
+// BatchOperation is what a batch does with every row.
+type BatchOperation int
+
+const (
+	// BatchSign signs the message of every row.
+	BatchSign BatchOperation = iota
+	// BatchVerify verifies the message, signature and address of every row.
+	BatchVerify
+)
+
+// String returns the name of the batch operation.
+func (op BatchOperation) String() string {
+	if op == BatchVerify {
+		return "verify"
+	}
+	return "sign"
+}
+
+// BatchRow is one input row of a batch.
+type BatchRow struct {
+	Line      int    `json:"line"`
+	Message   string `json:"message"`
+	Signature string `json:"signature,omitempty"`
+	Address   string `json:"address,omitempty"`
+}
+
+// Batch row statuses.
+const (
+	BatchStatusSigned  = "signed"
+	BatchStatusValid   = "valid"
+	BatchStatusInvalid = "invalid"
+	BatchStatusError   = "error"
+)
+
+// BatchResult is the outcome of one row of a batch.
+type BatchResult struct {
+	BatchRow
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ReadBatch reads batch rows in the given format. CSV input needs a header
+// row naming the message, signature and address columns; JSONL objects use
+// the same keys.
+func ReadBatch(r io.Reader, format BatchFormat) ([]BatchRow, error) {
+	if format == BatchJSONL {
+		return readBatchJSONL(r)
+	}
+	return readBatchCSV(r)
+}
+
+func readBatchCSV(r io.Reader) ([]BatchRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %v", err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	messageColumn, ok := columns["message"]
+	if !ok {
+		return nil, fmt.Errorf("CSV header has no message column")
+	}
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var rows []BatchRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %v", err)
+		}
+		line, _ := reader.FieldPos(0)
+		if messageColumn >= len(record) {
+			return nil, fmt.Errorf("CSV line %d has no message", line)
+		}
+		rows = append(rows, BatchRow{
+			Line:      line,
+			Message:   record[messageColumn],
+			Signature: field(record, "signature"),
+			Address:   field(record, "address"),
+		})
+	}
+	return rows, nil
+}
+
+func readBatchJSONL(r io.Reader) ([]BatchRow, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	var rows []BatchRow
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var row BatchRow
+		if err := json.Unmarshal([]byte(text), &row); err != nil {
+			return nil, fmt.Errorf("invalid JSON on line %d: %v", line, err)
+		}
+		row.Line = line
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read JSONL: %v", err)
+	}
+	return rows, nil
+}
+
+// WriteBatchResults writes results in the given format.
+func WriteBatchResults(w io.Writer, format BatchFormat, results []BatchResult) error {
+	if format == BatchJSONL {
+		encoder := json.NewEncoder(w)
+		for _, result := range results {
+			if err := encoder.Encode(result); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"line", "message", "signature", "address", "status", "error"})
+	for _, result := range results {
+		writer.Write([]string{strconv.Itoa(result.Line), result.Message, result.Signature, result.Address, result.Status, result.Error})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// RunBatch runs op over rows on a pool of workers goroutines and returns the
+// results in input order. privateKey is only used for signing. Each
+// finished row is added to progress, which callers may read concurrently.
+// Rows not started before ctx is cancelled are reported as errors.
+func RunBatch(ctx context.Context, op BatchOperation, rows []BatchRow, privateKey *ecdsa.PrivateKey, scheme SignatureScheme, workers int, progress *atomic.Uint64) []BatchResult {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if progress == nil {
+		progress = new(atomic.Uint64)
+	}
+
+	// Signed rows record the signer so the results can be verified as is
+	var signer string
+	if op == BatchSign && privateKey != nil {
+		signer = crypto.PubkeyToAddress(privateKey.PublicKey).Hex()
+	}
+
+	results := make([]BatchResult, len(rows))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = runBatchRow(op, rows[i], privateKey, signer, scheme)
+				progress.Add(1)
+			}
+		}()
+	}
+
+	next := 0
+feed:
+	for ; next < len(rows) && ctx.Err() == nil; next++ {
+		select {
+		case jobs <- next:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	for i := next; i < len(rows); i++ {
+		results[i] = BatchResult{BatchRow: rows[i], Status: BatchStatusError, Error: ctx.Err().Error()}
+	}
+	return results
+}
+
+func runBatchRow(op BatchOperation, row BatchRow, privateKey *ecdsa.PrivateKey, signer string, scheme SignatureScheme) BatchResult {
+	result := BatchResult{BatchRow: row}
+	fail := func(err error) BatchResult {
+		result.Status = BatchStatusError
+		result.Error = err.Error()
+		return result
+	}
+
+	if op == BatchSign {
+		if privateKey == nil {
+			return fail(errors.New("a private key is required for signing"))
+		}
+		signature, err := signMessageWithKey(privateKey, row.Message, scheme)
+		if err != nil {
+			return fail(err)
+		}
+		result.Signature = signature
+		result.Address = signer
+		result.Status = BatchStatusSigned
+		return result
+	}
+
+	if row.Signature == "" || row.Address == "" {
+		return fail(errors.New("signature and address are required"))
+	}
+	valid, err := VerifySignature(row.Message, row.Signature, row.Address, scheme)
+	if err != nil {
+		return fail(err)
+	}
+	result.Status = BatchStatusInvalid
+	if valid {
+		result.Status = BatchStatusValid
+	}
+	return result
+}
+
+// BatchSummary counts the outcomes of a batch.
+type BatchSummary struct {
+	Operation string        `json:"operation" yaml:"operation"`
+	Output    string        `json:"output" yaml:"output"`
+	Total     int           `json:"total" yaml:"total"`
+	Signed    int           `json:"signed" yaml:"signed"`
+	Valid     int           `json:"valid" yaml:"valid"`
+	Invalid   int           `json:"invalid" yaml:"invalid"`
+	Errors    int           `json:"errors" yaml:"errors"`
+	Duration  time.Duration `json:"-" yaml:"-"`
+}
+
+// SummarizeBatch counts results by status.
+func SummarizeBatch(op BatchOperation, results []BatchResult) *BatchSummary {
+	summary := &BatchSummary{Operation: op.String(), Total: len(results)}
+	for _, result := range results {
+		switch result.Status {
+		case BatchStatusSigned:
+			summary.Signed++
+		case BatchStatusValid:
+			summary.Valid++
+		case BatchStatusInvalid:
+			summary.Invalid++
+		default:
+			summary.Errors++
+		}
+	}
+	return summary
+}
+
+// Text implements Result.
+func (s *BatchSummary) Text() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Batch %s of %d rows finished in %s\n\n", s.Operation, s.Total, s.Duration.Round(time.Millisecond)))
+	if s.Operation == BatchSign.String() {
+		sb.WriteString(fmt.Sprintf("Signed : %d\n", s.Signed))
+	} else {
+		sb.WriteString(fmt.Sprintf("Valid  : %d\n", s.Valid))
+		sb.WriteString(fmt.Sprintf("Invalid: %d\n", s.Invalid))
+	}
+	sb.WriteString(fmt.Sprintf("Errors : %d\n", s.Errors))
+	if s.Output != "" {
+		sb.WriteString(fmt.Sprintf("\nResults written to %s", s.Output))
+	}
+	return sb.String()
+}
+
+// RunBatchFile reads rows from input, runs op over them and writes the
+// results to output, each in the format of its file extension.
+func RunBatchFile(ctx context.Context, op BatchOperation, input, output string, privateKey *ecdsa.PrivateKey, scheme SignatureScheme, workers int, progress *atomic.Uint64, total *atomic.Int64) (*BatchSummary, error) {
+	start := time.Now()
+	inFormat, err := BatchFormatFromPath(input)
+	if err != nil {
+		return nil, err
+	}
+	outFormat, err := BatchFormatFromPath(output)
+	if err != nil {
+		return nil, err
+	}
+
+	in, err := os.Open(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open input: %v", err)
+	}
+	rows, err := ReadBatch(in, inFormat)
+	in.Close()
+	if err != nil {
+		return nil, err
+	}
+	if total != nil {
+		total.Store(int64(len(rows)))
+	}
+
+	results := RunBatch(ctx, op, rows, privateKey, scheme, workers, progress)
+
+	out, err := os.Create(output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create output: %v", err)
+	}
+	if err := WriteBatchResults(out, outFormat, results); err != nil {
+		out.Close()
+		return nil, fmt.Errorf("failed to write results: %v", err)
+	}
+	if err := out.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write results: %v", err)
+	}
+
+	summary := SummarizeBatch(op, results)
+	summary.Output = output
+	summary.Duration = time.Since(start)
+	return summary, nil
+}
+
+// batchRun tracks a running batch of the TUI.
+type batchRun struct {
+	cancel   context.CancelFunc
+	progress *atomic.Uint64
+	total    *atomic.Int64
+}
+
+// batchResultMsg carries the outcome of a batch.
+type batchResultMsg struct {
+	run     *batchRun
+	summary *BatchSummary
+	err     error
+}
+
+func init() {
+	registerTool(170, "Batch Sign/Verify (CSV/JSONL)", newBatchScreen)
+}
+
+// batchFormFields are the labels of the fields on the batch form.
+var batchFormFields = []string{
+	"Operation (sign/verify)",
+	"Input File (.csv/.jsonl)",
+	"Output File (.csv/.jsonl)",
+	"Private Key (sign only)",
+	"Scheme (eip191/legacy-sha256)",
+	"Workers",
+}
+
+const (
+	batchFieldOperation = iota
+	batchFieldInput
+	batchFieldOutput
+	batchFieldPrivateKey
+	batchFieldScheme
+	batchFieldWorkers
+)
+
+// newBatchForm returns the initial values of the batch form.
+func newBatchForm() []string {
+	values := make([]string, len(batchFormFields))
+	values[batchFieldOperation] = "verify"
+	values[batchFieldScheme] = "eip191"
+	values[batchFieldWorkers] = strconv.Itoa(runtime.NumCPU())
+	return values
+}
+
+// batchScreen signs or verifies the rows of a file in the background.
+type batchScreen struct {
+	screenID
+	session *session
+	form    form
+	batch   *batchRun
+	content string
+}
+
+func newBatchScreen(s *session) Screen {
+	return batchScreen{screenID: newScreenID(), session: s, form: newForm(batchFormFields, newBatchForm(), batchFieldPrivateKey)}
+}
+
+func (b batchScreen) Init() tea.Cmd {
+	return nil
+}
+
+func (b batchScreen) Update(msg tea.Msg) (Screen, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.Type {
+		case tea.KeyCtrlC, tea.KeyEsc:
+			if b.batch != nil {
+				// Rows that have not started are reported as cancelled
+				b.batch.cancel()
+				return b, nil
+			}
+			return nil, nil
+		case tea.KeyEnter:
+			if b.batch != nil {
+				return b, nil
+			}
+			return b.enter()
+		default:
+			if b.batch == nil {
+				b.form = b.form.update(msg)
+			}
+		}
+	case searchTickMsg:
+		if b.batch != nil {
+			return b, searchTick()
+		}
+	case batchResultMsg:
+		if b.batch != msg.run {
+			return b, nil
+		}
+		b.batch.cancel()
+		b.batch = nil
+		if msg.err != nil {
+			b.content = fmt.Sprintf("Error: %v", msg.err)
+			return b, nil
+		}
+		return b.session.showResult(msg.summary), nil
+	}
+	return b, nil
+}
+
+func (b batchScreen) enter() (Screen, tea.Cmd) {
+	var op BatchOperation
+	switch strings.ToLower(strings.TrimSpace(b.form.values[batchFieldOperation])) {
+	case "sign":
+		op = BatchSign
+	case "verify":
+		op = BatchVerify
+	default:
+		b.content = "Error: Operation must be sign or verify."
+		return b, nil
+	}
+
+	input := strings.TrimSpace(b.form.values[batchFieldInput])
+	output := strings.TrimSpace(b.form.values[batchFieldOutput])
+	if input == "" || output == "" {
+		b.content = "Error: Input and output files are required."
+		return b, nil
+	}
+	scheme, err := ParseSignatureScheme(b.form.values[batchFieldScheme])
+	if err != nil {
+		b.content = fmt.Sprintf("Error: %v", err)
+		return b, nil
+	}
+	workers, err := strconv.Atoi(strings.TrimSpace(b.form.values[batchFieldWorkers]))
+	if err != nil || workers <= 0 {
+		b.content = "Error: Workers must be a positive number."
+		return b, nil
+	}
+	var privateKey *ecdsa.PrivateKey
+	if op == BatchSign {
+		// An empty field signs with the unlocked keystore key, if any
+		key := b.form.secret(batchFieldPrivateKey)
+		defer key.Wipe()
+		if privateKey, err = b.session.signingKey(Secret(bytes.TrimSpace(key))); err != nil {
+			b.content = signingKeyError(err)
+			return b, nil
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	run := &batchRun{cancel: cancel, progress: new(atomic.Uint64), total: new(atomic.Int64)}
+	b.batch = run
+	b.content = ""
+	return b, tea.Batch(searchTick(), func() tea.Msg {
+		defer wipeKey(privateKey)
+		summary, err := RunBatchFile(ctx, op, input, output, privateKey, scheme, workers, run.progress, run.total)
+		return batchResultMsg{run: run, summary: summary, err: err}
+	})
+}
+
+func (b batchScreen) wipe() {
+	b.form.wipe()
+}
+
+func (b batchScreen) View() string {
+	s := titleStyle.Render("Batch Sign/Verify (CSV/JSONL)") + "\n\n"
+	if b.batch == nil {
+		s += "Fill in the fields (Tab/Shift+Tab to move), Enter to start or Esc to cancel:\n"
+		s += b.session.unlockedHint() + "\n"
+		s += b.form.view()
+		if b.content != "" {
+			s += "\n" + b.content
+		}
+		return s
+	}
+
+	s += b.form.view() + "\n"
+	s += fmt.Sprintf("Processed %d of %d rows... press Esc to cancel.", b.batch.progress.Load(), b.batch.total.Load())
+	return s
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestReadBatchCSV(t *testing.T) {
+	input := "Address,Message,Signature\n0xabc,\"hello, world\",0x01\n0xdef,second\n"
+	rows, err := ReadBatch(strings.NewReader(input), BatchCSV)
+	if err != nil {
+		t.Fatalf("ReadBatch failed: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("Expected 2 rows, got %d", len(rows))
+	}
+	if rows[0].Line != 2 || rows[0].Message != "hello, world" || rows[0].Address != "0xabc" || rows[0].Signature != "0x01" {
+		t.Errorf("Unexpected first row %+v", rows[0])
+	}
+	if rows[1].Line != 3 || rows[1].Signature != "" {
+		t.Errorf("Unexpected second row %+v", rows[1])
+	}
+
+	if _, err := ReadBatch(strings.NewReader("address\n0xabc\n"), BatchCSV); err == nil {
+		t.Error("Expected an error without a message column")
+	}
+}
+
+func TestBatchFormatFromPath(t *testing.T) {
+	for path, want := range map[string]BatchFormat{"rows.csv": BatchCSV, "rows.JSONL": BatchJSONL, "rows.ndjson": BatchJSONL} {
+		if format, err := BatchFormatFromPath(path); err != nil || format != want {
+			t.Errorf("%s: expected format %d, got %d (%v)", path, want, format, err)
+		}
+	}
+	if _, err := BatchFormatFromPath("rows.json"); err == nil || !strings.Contains(err.Error(), "JSON Lines") {
+		t.Errorf("Expected .json files to be rejected, got %v", err)
+	}
+	if _, err := BatchFormatFromPath("rows.txt"); err == nil {
+		t.Error("Expected an error for an unknown extension")
+	}
+}
+
+func TestRunBatchSignVerify(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "messages.jsonl")
+	signed := filepath.Join(dir, "signed.csv")
+	verified := filepath.Join(dir, "verified.jsonl")
+
+	var jsonl bytes.Buffer
+	for _, message := range []string{"first", "second", "third"} {
+		jsonl.WriteString(`{"message":"` + message + `"}` + "\n\n")
+	}
+	if err := os.WriteFile(input, jsonl.Bytes(), 0600); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+
+	privateKey, err := crypto.HexToECDSA(cliTestKey)
+	if err != nil {
+		t.Fatalf("Failed to parse key: %v", err)
+	}
+	summary, err := RunBatchFile(context.Background(), BatchSign, input, signed, privateKey, SchemeEIP191, 2, nil, nil)
+	if err != nil {
+		t.Fatalf("RunBatchFile sign failed: %v", err)
+	}
+	if summary.Total != 3 || summary.Signed != 3 || summary.Invalid+summary.Errors != 0 {
+		t.Fatalf("Unexpected sign summary %+v", summary)
+	}
+
+	summary, err = RunBatchFile(context.Background(), BatchVerify, signed, verified, nil, SchemeEIP191, 2, nil, nil)
+	if err != nil {
+		t.Fatalf("RunBatchFile verify failed: %v", err)
+	}
+	if summary.Valid != 3 || summary.Invalid+summary.Errors != 0 {
+		t.Fatalf("Unexpected verify summary %+v", summary)
+	}
+
+	data, err := os.ReadFile(verified)
+	if err != nil {
+		t.Fatalf("Failed to read results: %v", err)
+	}
+	rows, err := ReadBatch(bytes.NewReader(data), BatchJSONL)
+	if err != nil || len(rows) != 3 || rows[1].Message != "second" || rows[1].Address != cliTestAddress {
+		t.Errorf("Unexpected results %s (%v)", data, err)
+	}
+}
+
+func TestRunBatchStatuses(t *testing.T) {
+	signature, err := SignMessage(cliTestKey, "hello", SchemeEIP191)
+	if err != nil {
+		t.Fatalf("SignMessage failed: %v", err)
+	}
+	rows := []BatchRow{
+		{Line: 1, Message: "hello", Signature: signature, Address: cliTestAddress},
+		{Line: 2, Message: "tampered", Signature: signature, Address: cliTestAddress},
+		{Line: 3, Message: "hello", Signature: "0x00", Address: cliTestAddress},
+		{Line: 4, Message: "hello"},
+	}
+	results := RunBatch(context.Background(), BatchVerify, rows, nil, SchemeEIP191, 3, nil)
+	want := []string{BatchStatusValid, BatchStatusInvalid, BatchStatusError, BatchStatusError}
+	for i, result := range results {
+		if result.Line != rows[i].Line || result.Status != want[i] {
+			t.Errorf("Row %d: expected %s, got %+v", i, want[i], result)
+		}
+	}
+	summary := SummarizeBatch(BatchVerify, results)
+	if summary.Valid != 1 || summary.Invalid != 1 || summary.Errors != 2 {
+		t.Errorf("Unexpected summary %+v", summary)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, result := range RunBatch(ctx, BatchVerify, rows, nil, SchemeEIP191, 1, nil) {
+		if result.Status == BatchStatusValid {
+			t.Errorf("Expected no rows to run after cancellation, got %+v", result)
+		}
+	}
+}
+
+func TestBatchScreenSignsWithUnlockedKey(t *testing.T) {
+	privateKey, err := crypto.HexToECDSA(cliTestKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &session{}
+	s.unlock(&unlockedKey{key: privateKey, address: crypto.PubkeyToAddress(privateKey.PublicKey)})
+	defer s.lock()
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, "messages.csv")
+	output := filepath.Join(dir, "signed.csv")
+	if err := os.WriteFile(input, []byte("message\nhello\n"), 0600); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+
+	screen := newBatchScreen(s).(batchScreen)
+	if !strings.Contains(screen.View(), "unlocked keystore account "+cliTestAddress) {
+		t.Errorf("Expected the batch screen to offer the unlocked key, got:\n%s", screen.View())
+	}
+	screen.form.values[batchFieldOperation] = "sign"
+	screen.form.values[batchFieldInput] = input
+	screen.form.values[batchFieldOutput] = output
+	next, cmd := screen.enter()
+	if cmd == nil || next.(batchScreen).batch == nil {
+		t.Fatalf("Expected the batch to start without a typed key, got:\n%s", next.View())
+	}
+	for _, cmd := range cmd().(tea.BatchMsg) {
+		if msg, ok := cmd().(batchResultMsg); ok {
+			if msg.err != nil || msg.summary.Signed != 1 {
+				t.Fatalf("Expected one signed row, got %+v, %v", msg.summary, msg.err)
+			}
+		}
+	}
+	want, err := signMessageWithKey(privateKey, "hello", SchemeEIP191)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(output); err != nil || !strings.Contains(string(data), want) {
+		t.Errorf("Expected a signature by the unlocked key, got %s (%v)", data, err)
+	}
+}
@@ -0,0 +1,463 @@
+// cli.go
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"example.com/ethgotools/farcaster"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Exit codes of the CLI follow grep: 0 for success or a valid result, 1
+// for a negative result such as an invalid signature, 2 for errors.
+const (
+	exitOK      = 0
+	exitInvalid = 1
+	exitError   = 2
+)
+
+// errUsage is returned by commands whose arguments are wrong, after the
+// flag package has already printed the problem.
+var errUsage = errors.New("invalid usage")
+
+// cliCommand is a non-interactive subcommand.
+type cliCommand struct {
+	name    string
+	summary string
+	run     func(cli *cliContext, args []string) (int, error)
+}
+
+// cliContext holds the streams a command reads from and writes to.
+type cliContext struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+
+	stdinUsed bool
+}
+
+var cliCommands = []cliCommand{
+	{"address", "Print the address of a private key", runAddress},
+	{"generate", "Generate a new private key", runGenerate},
+	{"sign", "Sign a message", runSign},
+	{"verify", "Verify a message signature", runVerify},
+	{"farcaster", "Look up a Farcaster account", runFarcaster},
+	{"batch", "Sign or verify every row of a CSV/JSONL file", runBatch},
+}
+
+// runCLI runs the subcommand named by args[0] and returns the process exit
+// code.
+func runCLI(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	cli := &cliContext{stdin: stdin, stdout: stdout, stderr: stderr}
+
+	name := args[0]
+	if name == "help" || name == "-h" || name == "--help" {
+		cli.usage()
+		return exitOK
+	}
+	for _, command := range cliCommands {
+		if command.name != name {
+			continue
+		}
+		code, err := command.run(cli, args[1:])
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		if err != nil {
+			if !errors.Is(err, errUsage) {
+				fmt.Fprintf(stderr, "Error: %v\n", err)
+			}
+			return exitError
+		}
+		return code
+	}
+
+	fmt.Fprintf(stderr, "Error: unknown command %q\n\n", name)
+	cli.usage()
+	return exitError
+}
+
+func (cli *cliContext) usage() {
+	fmt.Fprintln(cli.stderr, "Usage: ethgotools [command] [flags]")
+	fmt.Fprintln(cli.stderr, "\nRun without a command to start the interactive interface.")
+	fmt.Fprintln(cli.stderr, "\nCommands:")
+	for _, command := range cliCommands {
+		fmt.Fprintf(cli.stderr, "  %-10s %s\n", command.name, command.summary)
+	}
+	fmt.Fprintln(cli.stderr, "\nRun 'ethgotools [command] -h' for the flags of a command.")
+}
+
+// newFlagSet returns a flag set for a command that reports its errors on
+// the CLI's stderr.
+func (cli *cliContext) newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("ethgotools "+name, flag.ContinueOnError)
+	fs.SetOutput(cli.stderr)
+	return fs
+}
+
+// parse parses args into fs, mapping flag errors to errUsage, and rejects
+// more than maxArgs positional arguments.
+func (cli *cliContext) parse(fs *flag.FlagSet, args []string, maxArgs int) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return errUsage
+	}
+	if fs.NArg() > maxArgs {
+		fmt.Fprintf(cli.stderr, "unexpected argument %q\n", fs.Arg(maxArgs))
+		fs.Usage()
+		return errUsage
+	}
+	return nil
+}
+
+// resolve returns value, falling back to the environment variable env and
+// then to stdin when value is empty or "-". Only one input per command can
+// come from stdin. Trailing newlines are cut from stdin, so keys can be
+// piped in with echo.
+func (cli *cliContext) resolve(value, env, what string) (string, error) {
+	return cli.input(value, env, what, true)
+}
+
+// resolveMessage is like resolve for a message to sign or verify, which is
+// read from stdin byte for byte: a trailing newline is part of the message.
+func (cli *cliContext) resolveMessage(value, what string) (string, error) {
+	return cli.input(value, "", what, false)
+}
+
+func (cli *cliContext) input(value, env, what string, trim bool) (string, error) {
+	if value == "" && env != "" {
+		value = os.Getenv(env)
+	}
+	if value != "" && value != "-" {
+		return value, nil
+	}
+	if cli.stdinUsed {
+		return "", fmt.Errorf("%s is required", what)
+	}
+	cli.stdinUsed = true
+	data, err := io.ReadAll(bufio.NewReader(cli.stdin))
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s from stdin: %v", what, err)
+	}
+	value = string(data)
+	if trim {
+		value = strings.TrimRight(value, "\r\n")
+	}
+	if value == "" {
+		return "", fmt.Errorf("%s is required", what)
+	}
+	return value, nil
+}
+
+// privateKeyFlags are the ways a command can be given a private key.
+type privateKeyFlags struct {
+	key      *string
+	keystore *string
+	password *string
+}
+
+func addPrivateKeyFlags(fs *flag.FlagSet) privateKeyFlags {
+	return privateKeyFlags{
+		key:      fs.String("key", "", "private key in hex (default $ETH_PRIVATE_KEY, or stdin)"),
+		keystore: fs.String("keystore", "", "keystore V3 file to unlock instead of -key"),
+		password: fs.String("password", "", "keystore password (default $ETH_KEYSTORE_PASSWORD)"),
+	}
+}
+
+// privateKeyHex returns the hex private key selected by the flags.
+func (cli *cliContext) privateKeyHex(flags privateKeyFlags) (string, error) {
+	if *flags.keystore != "" {
+		password := *flags.password
+		if password == "" {
+			password = os.Getenv("ETH_KEYSTORE_PASSWORD")
+		}
+		privateKey, err := UnlockKeystoreFile(*flags.keystore, password)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%x", crypto.FromECDSA(privateKey)), nil
+	}
+
+	key, err := cli.resolve(*flags.key, "ETH_PRIVATE_KEY", "private key")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimPrefix(strings.TrimSpace(key), "0x"), nil
+}
+
+// addOutputFlag registers the -output flag, with -o as a shorthand.
+func addOutputFlag(fs *flag.FlagSet) *OutputFormat {
+	output := new(OutputFormat)
+	fs.Var(output, "output", "output format: text, json or yaml")
+	fs.Var(output, "o", "shorthand for -output")
+	return output
+}
+
+// print writes r to stdout in the given output format.
+func (cli *cliContext) print(r Result, format OutputFormat) error {
+	s, err := RenderResult(r, format)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(cli.stdout, strings.TrimSuffix(s, "\n"))
+	return err
+}
+
+// ParseSignatureScheme parses the CLI name of a signature scheme.
+func ParseSignatureScheme(s string) (SignatureScheme, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "eip191":
+		return SchemeEIP191, nil
+	case "legacy-sha256":
+		return SchemeLegacySHA256, nil
+	default:
+		return SchemeEIP191, fmt.Errorf("unknown signature scheme %q (want eip191 or legacy-sha256)", s)
+	}
+}
+
+// name returns the CLI name of the scheme, as accepted by
+// ParseSignatureScheme.
+func (s SignatureScheme) name() string {
+	if s == SchemeLegacySHA256 {
+		return "legacy-sha256"
+	}
+	return "eip191"
+}
+
+func runAddress(cli *cliContext, args []string) (int, error) {
+	fs := cli.newFlagSet("address")
+	keyFlags := addPrivateKeyFlags(fs)
+	output := addOutputFlag(fs)
+	if err := cli.parse(fs, args, 0); err != nil {
+		return exitError, err
+	}
+
+	privateKeyHex, err := cli.privateKeyHex(keyFlags)
+	if err != nil {
+		return exitError, err
+	}
+	privateKey, err := crypto.HexToECDSA(privateKeyHex)
+	if err != nil {
+		return exitError, fmt.Errorf("invalid private key: %v", err)
+	}
+	address := crypto.PubkeyToAddress(privateKey.PublicKey).Hex()
+	if *output == OutputText {
+		// Print the bare address so it can be captured in shell variables
+		fmt.Fprintln(cli.stdout, address)
+		return exitOK, nil
+	}
+	return exitOK, cli.print(&AddressResult{Address: address}, *output)
+}
+
+func runGenerate(cli *cliContext, args []string) (int, error) {
+	fs := cli.newFlagSet("generate")
+	output := addOutputFlag(fs)
+	if err := cli.parse(fs, args, 0); err != nil {
+		return exitError, err
+	}
+
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		return exitError, fmt.Errorf("failed to generate private key: %v", err)
+	}
+	return exitOK, cli.print(&KeyResult{
+		PrivateKey: privateKeySecret(privateKey),
+		Address:    crypto.PubkeyToAddress(privateKey.PublicKey).Hex(),
+	}, *output)
+}
+
+func runSign(cli *cliContext, args []string) (int, error) {
+	fs := cli.newFlagSet("sign")
+	keyFlags := addPrivateKeyFlags(fs)
+	message := fs.String("message", "", "message to sign (default stdin)")
+	schemeName := fs.String("scheme", "eip191", "signature scheme: eip191 or legacy-sha256")
+	output := addOutputFlag(fs)
+	if err := cli.parse(fs, args, 0); err != nil {
+		return exitError, err
+	}
+
+	scheme, err := ParseSignatureScheme(*schemeName)
+	if err != nil {
+		return exitError, err
+	}
+	privateKeyHex, err := cli.privateKeyHex(keyFlags)
+	if err != nil {
+		return exitError, err
+	}
+	msg, err := cli.resolveMessage(*message, "message")
+	if err != nil {
+		return exitError, err
+	}
+
+	signature, err := SignMessage(privateKeyHex, msg, scheme)
+	if err != nil {
+		return exitError, err
+	}
+	if *output == OutputText {
+		// Print the bare signature so it can be captured in shell variables
+		fmt.Fprintln(cli.stdout, signature)
+		return exitOK, nil
+	}
+	return exitOK, cli.print(&SignatureResult{Scheme: scheme.name(), Signature: signature}, *output)
+}
+
+func runVerify(cli *cliContext, args []string) (int, error) {
+	fs := cli.newFlagSet("verify")
+	message := fs.String("message", "", "message that was signed (default stdin)")
+	signature := fs.String("signature", "", "signature in hex")
+	address := fs.String("address", "", "address of the signer")
+	schemeName := fs.String("scheme", "eip191", "signature scheme: eip191 or legacy-sha256")
+	rpcURL := fs.String("rpc", os.Getenv("ETH_RPC_URL"), "JSON-RPC endpoint for ERC-1271/ERC-6492 smart accounts (default $ETH_RPC_URL)")
+	output := addOutputFlag(fs)
+	if err := cli.parse(fs, args, 0); err != nil {
+		return exitError, err
+	}
+
+	if *signature == "" || *address == "" {
+		fmt.Fprintln(cli.stderr, "-signature and -address are required")
+		fs.Usage()
+		return exitError, errUsage
+	}
+	scheme, err := ParseSignatureScheme(*schemeName)
+	if err != nil {
+		return exitError, err
+	}
+	msg, err := cli.resolveMessage(*message, "message")
+	if err != nil {
+		return exitError, err
+	}
+
+	result := &VerificationResult{Address: *address, Scheme: scheme.name()}
+	if *rpcURL != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		var signerType SignerType
+		result.Valid, signerType, err = VerifySignatureOnChain(ctx, *rpcURL, msg, *signature, *address, scheme)
+		result.SignerType = signerType.String()
+	} else {
+		result.Valid, err = VerifySignature(msg, *signature, *address, scheme)
+	}
+	if err != nil {
+		return exitError, err
+	}
+
+	if err := cli.print(result, *output); err != nil {
+		return exitError, err
+	}
+	if !result.Valid {
+		return exitInvalid, nil
+	}
+	return exitOK, nil
+}
+
+func runFarcaster(cli *cliContext, args []string) (int, error) {
+	fs := cli.newFlagSet("farcaster")
+	output := addOutputFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(cli.stderr, "Usage: ethgotools farcaster [flags] <username>")
+		fmt.Fprintln(cli.stderr, "\nUses the provider chosen by $FARCASTER_PROVIDER: airstack (the default,\nrequires $AIRSTACK_API_KEY), neynar (requires $NEYNAR_API_KEY) or hub\n(requires $FARCASTER_HUB_URL).")
+		fs.PrintDefaults()
+	}
+	if err := cli.parse(fs, args, 1); err != nil {
+		return exitError, err
+	}
+	fname := strings.TrimSpace(fs.Arg(0))
+	if fs.NArg() != 1 || fname == "" {
+		fs.Usage()
+		return exitError, errUsage
+	}
+
+	provider, err := newFarcasterProvider()
+	if err != nil {
+		return exitError, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), farcasterLookupTimeout)
+	defer cancel()
+	account, err := farcaster.Lookup(ctx, provider, fname, farcasterLookupLimit)
+	if errors.Is(err, farcaster.ErrNotFound) {
+		fmt.Fprintln(cli.stdout, "No data found for the provided Farcaster username.")
+		return exitInvalid, nil
+	}
+	if err != nil {
+		return exitError, fmt.Errorf("failed to query %s: %s", provider.Name(), describeFarcasterError(err))
+	}
+	return exitOK, cli.print(NewFarcasterResult(fname, provider.Name(), account), *output)
+}
+
+func runBatch(cli *cliContext, args []string) (int, error) {
+	fs := cli.newFlagSet("batch")
+	keyFlags := addPrivateKeyFlags(fs)
+	input := fs.String("in", "", "input file (.csv or .jsonl)")
+	outputFile := fs.String("out", "", "results file (.csv or .jsonl)")
+	schemeName := fs.String("scheme", "eip191", "signature scheme: eip191 or legacy-sha256")
+	workers := fs.Int("workers", runtime.NumCPU(), "number of rows processed concurrently")
+	output := addOutputFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(cli.stderr, "Usage: ethgotools batch [flags] sign|verify")
+		fmt.Fprintln(cli.stderr, "\nRows have message, signature and address columns (CSV with a header row) or keys (JSONL).")
+		fs.PrintDefaults()
+	}
+	if err := cli.parse(fs, args, 1); err != nil {
+		return exitError, err
+	}
+
+	var op BatchOperation
+	switch fs.Arg(0) {
+	case "sign":
+		op = BatchSign
+	case "verify":
+		op = BatchVerify
+	default:
+		fs.Usage()
+		return exitError, errUsage
+	}
+	if *input == "" || *outputFile == "" || *workers <= 0 {
+		fmt.Fprintln(cli.stderr, "-in and -out are required and -workers must be positive")
+		fs.Usage()
+		return exitError, errUsage
+	}
+	scheme, err := ParseSignatureScheme(*schemeName)
+	if err != nil {
+		return exitError, err
+	}
+	var privateKey *ecdsa.PrivateKey
+	if op == BatchSign {
+		privateKeyHex, err := cli.privateKeyHex(keyFlags)
+		if err != nil {
+			return exitError, err
+		}
+		if privateKey, err = crypto.HexToECDSA(privateKeyHex); err != nil {
+			return exitError, fmt.Errorf("invalid private key: %v", err)
+		}
+	}
+
+	summary, err := RunBatchFile(context.Background(), op, *input, *outputFile, privateKey, scheme, *workers, nil, nil)
+	if err != nil {
+		return exitError, err
+	}
+	if err := cli.print(summary, *output); err != nil {
+		return exitError, err
+	}
+	// Rows that could not be processed are not a false verification
+	if summary.Errors > 0 {
+		return exitError, fmt.Errorf("%d of %d rows could not be processed, see %s", summary.Errors, summary.Total, summary.Output)
+	}
+	if summary.Invalid > 0 {
+		return exitInvalid, nil
+	}
+	return exitOK, nil
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"example.com/ethgotools/airstack/airstacktest"
+)
+
+const cliTestKey = "4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+const cliTestAddress = "0x2c7536E3605D9C16a7a3D7b1898e529396a65c23"
+
+func runCLITest(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := runCLI(args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestCLIAddress(t *testing.T) {
+	code, out, _ := runCLITest(t, "", "address", "-key", cliTestKey)
+	if code != exitOK || strings.TrimSpace(out) != cliTestAddress {
+		t.Errorf("Expected %s and exit 0, got %q and exit %d", cliTestAddress, out, code)
+	}
+
+	// The key can also be piped in
+	code, out, _ = runCLITest(t, "0x"+cliTestKey+"\n", "address")
+	if code != exitOK || strings.TrimSpace(out) != cliTestAddress {
+		t.Errorf("Expected %s and exit 0 from stdin, got %q and exit %d", cliTestAddress, out, code)
+	}
+
+	code, _, errOut := runCLITest(t, "", "address", "-key", "zz")
+	if code != exitError || !strings.Contains(errOut, "invalid private key") {
+		t.Errorf("Expected exit 2 for an invalid key, got %d (%q)", code, errOut)
+	}
+}
+
+func TestCLISignVerify(t *testing.T) {
+	code, out, errOut := runCLITest(t, "Hello, Ethereum!", "sign", "-key", cliTestKey)
+	if code != exitOK {
+		t.Fatalf("sign exited with %d: %s", code, errOut)
+	}
+	signature := strings.TrimSpace(out)
+
+	t.Setenv("ETH_RPC_URL", "")
+	code, out, _ = runCLITest(t, "Hello, Ethereum!", "verify", "-signature", signature, "-address", cliTestAddress)
+	if code != exitOK || strings.TrimSpace(out) != "Signature is valid." {
+		t.Errorf("Expected a valid signature, got %q and exit %d", out, code)
+	}
+
+	code, out, _ = runCLITest(t, "", "verify", "-message", "Tampered", "-signature", signature, "-address", cliTestAddress)
+	if code != exitInvalid || strings.TrimSpace(out) != "Signature is invalid." {
+		t.Errorf("Expected an invalid signature and exit 1, got %q and exit %d", out, code)
+	}
+
+	code, _, _ = runCLITest(t, "", "verify", "-message", "Hello, Ethereum!", "-signature", signature)
+	if code != exitError {
+		t.Errorf("Expected exit 2 without -address, got %d", code)
+	}
+}
+
+func TestCLIMessageFromStdin(t *testing.T) {
+	// Messages are signed byte for byte, trailing newline included
+	t.Setenv("ETH_RPC_URL", "")
+	code, out, errOut := runCLITest(t, "hi\n", "sign", "-key", cliTestKey)
+	if code != exitOK {
+		t.Fatalf("sign exited with %d: %s", code, errOut)
+	}
+	signature := strings.TrimSpace(out)
+
+	code, out, _ = runCLITest(t, "hi\n", "verify", "-signature", signature, "-address", cliTestAddress)
+	if code != exitOK || strings.TrimSpace(out) != "Signature is valid." {
+		t.Errorf("Expected the signature of \"hi\\n\" to be valid, got %q and exit %d", out, code)
+	}
+	code, out, _ = runCLITest(t, "", "verify", "-message", "hi", "-signature", signature, "-address", cliTestAddress)
+	if code != exitInvalid || strings.TrimSpace(out) != "Signature is invalid." {
+		t.Errorf("Expected the signature of \"hi\\n\" not to match \"hi\", got %q and exit %d", out, code)
+	}
+}
+
+func TestCLIBatchExitCodes(t *testing.T) {
+	signature, err := SignMessage(cliTestKey, "hello", SchemeEIP191)
+	if err != nil {
+		t.Fatalf("SignMessage failed: %v", err)
+	}
+	dir := t.TempDir()
+	batch := func(rows ...string) (int, string) {
+		t.Helper()
+		input := filepath.Join(dir, "rows.jsonl")
+		if err := os.WriteFile(input, []byte(strings.Join(rows, "\n")), 0600); err != nil {
+			t.Fatalf("Failed to write input: %v", err)
+		}
+		code, _, errOut := runCLITest(t, "", "batch", "-in", input, "-out", filepath.Join(dir, "results.csv"), "verify")
+		return code, errOut
+	}
+	valid := `{"message":"hello","signature":"` + signature + `","address":"` + cliTestAddress + `"}`
+	invalid := `{"message":"tampered","signature":"` + signature + `","address":"` + cliTestAddress + `"}`
+	broken := `{"message":"hello","signature":"0x00","address":"` + cliTestAddress + `"}`
+
+	if code, _ := batch(valid); code != exitOK {
+		t.Errorf("Expected exit 0 for valid rows, got %d", code)
+	}
+	if code, _ := batch(valid, invalid); code != exitInvalid {
+		t.Errorf("Expected exit 1 for an invalid row, got %d", code)
+	}
+	if code, errOut := batch(valid, invalid, broken); code != exitError || !strings.Contains(errOut, "1 of 3 rows could not be processed") {
+		t.Errorf("Expected exit 2 for a row that could not be processed, got %d (%q)", code, errOut)
+	}
+}
+
+func TestCLIUsage(t *testing.T) {
+	if code, _, _ := runCLITest(t, "", "unknown"); code != exitError {
+		t.Errorf("Expected exit 2 for an unknown command, got %d", code)
+	}
+	if code, _, _ := runCLITest(t, "", "sign", "-bogus"); code != exitError {
+		t.Errorf("Expected exit 2 for an unknown flag, got %d", code)
+	}
+	if code, _, errOut := runCLITest(t, "", "help"); code != exitOK || !strings.Contains(errOut, "farcaster") {
+		t.Errorf("Expected help to list the commands, got exit %d (%q)", code, errOut)
+	}
+	if code, out, _ := runCLITest(t, "", "generate"); code != exitOK || !strings.Contains(out, "Address") {
+		t.Errorf("Expected generate to print a key, got exit %d (%q)", code, out)
+	}
+}
+
+func TestCLIOutputFormat(t *testing.T) {
+	code, out, _ := runCLITest(t, "", "address", "-key", cliTestKey, "-o", "json")
+	if code != exitOK || !strings.Contains(out, `"address": "`+cliTestAddress+`"`) {
+		t.Errorf("Expected JSON output, got %q and exit %d", out, code)
+	}
+
+	t.Setenv("ETH_RPC_URL", "")
+	_, signature, _ := runCLITest(t, "", "sign", "-key", cliTestKey, "-message", "Hello, Ethereum!")
+	code, out, _ = runCLITest(t, "", "verify", "-message", "Tampered", "-signature", strings.TrimSpace(signature), "-address", cliTestAddress, "-output", "yaml")
+	if code != exitInvalid || !strings.Contains(out, "valid: false") {
+		t.Errorf("Expected YAML output and exit 1, got %q and exit %d", out, code)
+	}
+
+	if code, _, _ := runCLITest(t, "", "generate", "-o", "xml"); code != exitError {
+		t.Errorf("Expected exit 2 for an unknown output format, got %d", code)
+	}
+}
+
+func TestCLIFarcaster(t *testing.T) {
+	server := airstacktest.NewServer()
+	defer server.Close()
+	t.Setenv("FARCASTER_PROVIDER", "airstack")
+	t.Setenv("AIRSTACK_API_KEY", airstacktest.APIKey)
+	t.Setenv("AIRSTACK_URL", server.URL)
+
+	code, out, _ := runCLITest(t, "", "farcaster", "-o", "json", "v")
+	if code != exitOK || !strings.Contains(out, `"profileName": "v"`) || !strings.Contains(out, `"casts": []`) {
+		t.Errorf("Expected the profile as JSON and exit 0, got %q and exit %d", out, code)
+	}
+
+	if code, _, _ := runCLITest(t, "", "farcaster", " "); code != exitError || len(server.Requests()) != 3 {
+		t.Errorf("Expected exit 2 without a request for a blank name, got %d after %d requests", code, len(server.Requests()))
+	}
+
+	if code, out, _ := runCLITest(t, "", "farcaster", "nobody"); code != exitInvalid || !strings.Contains(out, "No data found") {
+		t.Errorf("Expected no data and exit %d, got %q and exit %d", exitInvalid, out, code)
+	}
+}
+
+func TestCLIFarcasterProviders(t *testing.T) {
+	hub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/userNameProofByName":
+			w.Write([]byte(`{"name":"v","fid":2,"type":"USERNAME_TYPE_FNAME"}`))
+		case "/v1/userDataByFid":
+			w.Write([]byte(`{"messages":[{"data":{"type":"MESSAGE_TYPE_USER_DATA_ADD","userDataBody":{"type":"USER_DATA_TYPE_DISPLAY","value":"Varun Srinivasan"}}}]}`))
+		default:
+			w.Write([]byte(`{"messages":[]}`))
+		}
+	}))
+	defer hub.Close()
+	t.Setenv("FARCASTER_PROVIDER", "hub")
+	t.Setenv("FARCASTER_HUB_URL", hub.URL)
+
+	code, out, _ := runCLITest(t, "", "farcaster", "v")
+	for _, want := range []string{"from Farcaster Hub", "Display Name   : Varun Srinivasan", "Follower Count : unknown", "Channels are not available"} {
+		if code != exitOK || !strings.Contains(out, want) {
+			t.Errorf("Expected %q and exit 0, got %q and exit %d", want, out, code)
+		}
+	}
+
+	t.Setenv("FARCASTER_PROVIDER", "neynar")
+	t.Setenv("NEYNAR_API_KEY", "")
+	if code, _, errOut := runCLITest(t, "", "farcaster", "v"); code != exitError || !strings.Contains(errOut, "NEYNAR_API_KEY not set") {
+		t.Errorf("Expected an error without an API key, got %q and exit %d", errOut, code)
+	}
+	t.Setenv("FARCASTER_PROVIDER", "warpcast")
+	if code, _, errOut := runCLITest(t, "", "farcaster", "v"); code != exitError || !strings.Contains(errOut, "unknown FARCASTER_PROVIDER") {
+		t.Errorf("Expected an error for an unknown provider, got %q and exit %d", errOut, code)
+	}
+}
@@ -0,0 +1,89 @@
+// clipboard.go
+
+package main
+
+import (
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aymanbagabas/go-osc52/v2"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// DefaultClipboardClear is how long a copied secret stays on the clipboard
+// unless $ETH_CLIPBOARD_CLEAR says otherwise.
+const DefaultClipboardClear = 30 * time.Second
+
+// CopyField is a value of a result that can be copied to the clipboard.
+type CopyField struct {
+	Label  string
+	Value  string
+	Secret bool
+}
+
+// Copyable is implemented by results with fields that can be copied to the
+// clipboard on their own.
+type Copyable interface {
+	CopyFields() []CopyField
+}
+
+// clipboardClearDelay returns how long copied secrets are kept on the
+// clipboard, from $ETH_CLIPBOARD_CLEAR (a duration such as 45s; 0 keeps them).
+func clipboardClearDelay() time.Duration {
+	if d, err := time.ParseDuration(os.Getenv("ETH_CLIPBOARD_CLEAR")); err == nil && d >= 0 {
+		return d
+	}
+	return DefaultClipboardClear
+}
+
+// osc52Sequence wraps seq for terminal multiplexers, which otherwise swallow
+// OSC 52 instead of passing it on to the terminal.
+func osc52Sequence(seq osc52.Sequence) osc52.Sequence {
+	if os.Getenv("TMUX") != "" {
+		return seq.Tmux()
+	}
+	if strings.HasPrefix(os.Getenv("TERM"), "screen") {
+		return seq.Screen()
+	}
+	return seq
+}
+
+// clipboardClearMsg asks the TUI to clear the clipboard if it still holds
+// the secret of the given copy.
+type clipboardClearMsg struct {
+	copy int
+}
+
+// terminal returns where escape sequences for the terminal are written: the
+// output of the TUI, or nowhere for a session without one.
+func (s *session) terminal() io.Writer {
+	if s.clipboard != nil {
+		return s.clipboard
+	}
+	return io.Discard
+}
+
+// copyToClipboard sets the clipboard of the terminal to value with OSC 52,
+// which also works over SSH. For secrets it returns a command that clears
+// the clipboard again after the configured delay.
+func (s *session) copyToClipboard(value string, secret bool) (tea.Cmd, error) {
+	if _, err := osc52Sequence(osc52.New(value)).WriteTo(s.terminal()); err != nil {
+		return nil, err
+	}
+	s.copies++
+	if !secret || s.clipboardClear <= 0 {
+		return nil, nil
+	}
+	n := s.copies
+	return tea.Tick(s.clipboardClear, func(time.Time) tea.Msg { return clipboardClearMsg{copy: n} }), nil
+}
+
+// clearClipboard empties the clipboard unless something else was copied
+// after the secret it was scheduled for.
+func (s *session) clearClipboard(msg clipboardClearMsg) {
+	if msg.copy == s.copies {
+		osc52Sequence(osc52.Clear()).WriteTo(s.terminal())
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestCopyToClipboard(t *testing.T) {
+	t.Setenv("TMUX", "")
+	t.Setenv("TERM", "xterm-256color")
+
+	var terminal bytes.Buffer
+	s := &session{clipboard: &terminal, clipboardClear: time.Minute}
+	screen := s.showResult(&KeyResult{PrivateKey: Secret(cliTestKey), Address: cliTestAddress})
+	if !strings.Contains(screen.View(), "1 Private Key, 2 Address") {
+		t.Errorf("Expected the fields to be listed, got:\n%s", screen.View())
+	}
+
+	// Addresses are not secret, so they stay on the clipboard
+	screen, cmd := screen.Update(runes("2"))
+	want := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(cliTestAddress)) + "\x07"
+	if terminal.String() != want || cmd != nil {
+		t.Errorf("Expected %q and no clear, got %q", want, terminal.String())
+	}
+
+	terminal.Reset()
+	screen, cmd = screen.Update(runes("1"))
+	if !strings.Contains(terminal.String(), base64.StdEncoding.EncodeToString([]byte(cliTestKey))) || cmd == nil {
+		t.Fatalf("Expected the key to be copied with a clear scheduled, got %q", terminal.String())
+	}
+	if !strings.Contains(screen.View(), "cleared in 1m0s") {
+		t.Errorf("Expected the footer to mention the clear, got:\n%s", screen.View())
+	}
+
+	// The clear still happens after returning to the menu
+	m := model{session: s}
+	terminal.Reset()
+	m.Update(clipboardClearMsg{copy: s.copies})
+	if terminal.String() != "\x1b]52;c;!\x07" {
+		t.Errorf("Expected the clipboard to be cleared, got %q", terminal.String())
+	}
+
+	// A clear scheduled before a later copy is skipped
+	terminal.Reset()
+	s.copyToClipboard("later", false)
+	terminal.Reset()
+	m.Update(clipboardClearMsg{copy: s.copies - 1})
+	if terminal.Len() != 0 {
+		t.Errorf("Expected a stale clear to be ignored, got %q", terminal.String())
+	}
+}
+
+func TestCopySecretText(t *testing.T) {
+	var terminal bytes.Buffer
+	s := &session{clipboard: &terminal, clipboardClear: time.Second}
+	if _, cmd := s.showResult(&HDWalletResult{Mnemonic: Secret("mnemonic")}).Update(runes("c")); cmd == nil {
+		t.Error("Expected copying secret output to schedule a clear")
+	}
+	if _, cmd := s.showText("address").Update(runes("c")); cmd != nil {
+		t.Error("Expected copying plain output to stay on the clipboard")
+	}
+	if _, cmd := s.showText("address").Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("3")}); cmd != nil {
+		t.Error("Expected keys without a field to be ignored")
+	}
+}
+
+func TestCopyThroughProgramOutput(t *testing.T) {
+	t.Setenv("TMUX", "")
+	t.Setenv("TERM", "xterm-256color")
+
+	f, err := os.CreateTemp(t.TempDir(), "terminal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	out := &terminalOutput{File: f}
+	s := newSession(out)
+	if _, err := s.copyToClipboard(cliTestAddress, false); err != nil {
+		t.Fatalf("copyToClipboard failed: %v", err)
+	}
+	written, err := os.ReadFile(f.Name())
+	want := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(cliTestAddress)) + "\x07"
+	if err != nil || string(written) != want {
+		t.Errorf("Expected %q on the program output, got %q (%v)", want, written, err)
+	}
+}
@@ -0,0 +1,508 @@
+// create2.go
+
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var (
+	// DeterministicDeploymentProxy is the canonical CREATE2 factory deployed
+	// with a keyless transaction on most EVM chains. It uses the salt as is.
+	DeterministicDeploymentProxy = common.HexToAddress("0x4e59b44847b379578588920cA78FbF26c0B4956C")
+	// CreateXFactory is the CreateX factory, which guards salts before
+	// passing them to CREATE2.
+	CreateXFactory = common.HexToAddress("0xba5Ed099633D3B313e4D5F7bdc1305d3c28ba5Ed")
+)
+
+// create2Presets maps the factory names accepted in place of an address.
+var create2Presets = map[string]common.Address{
+	"proxy":   DeterministicDeploymentProxy,
+	"createx": CreateXFactory,
+}
+
+// ParseDeployer parses a deployer address or the name of a preset factory.
+func ParseDeployer(s string) (common.Address, error) {
+	s = strings.TrimSpace(s)
+	if address, ok := create2Presets[strings.ToLower(s)]; ok {
+		return address, nil
+	}
+	if !common.IsHexAddress(s) {
+		return common.Address{}, fmt.Errorf("invalid deployer address")
+	}
+	return common.HexToAddress(s), nil
+}
+
+// ParseInitCodeHash parses a 32-byte init code hash, or hashes init code of
+// any other length.
+func ParseInitCodeHash(s string) (common.Hash, error) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "0x") {
+		s = "0x" + s
+	}
+	data, err := hexutil.Decode(s)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("invalid init code: %v", err)
+	}
+	if len(data) == common.HashLength {
+		return common.BytesToHash(data), nil
+	}
+	return crypto.Keccak256Hash(data), nil
+}
+
+// Create2Params are the inputs of a CREATE2 deployment.
+type Create2Params struct {
+	Factory      common.Address
+	Salt         common.Hash
+	InitCodeHash common.Hash
+
+	// Sender and ChainID are only used by the CreateX factory, whose salt
+	// guard mixes them in depending on the salt.
+	Sender  common.Address
+	ChainID *big.Int
+}
+
+// EffectiveSalt returns the salt the factory passes to CREATE2.
+func (p *Create2Params) EffectiveSalt() (common.Hash, error) {
+	if p.Factory == CreateXFactory {
+		return CreateXGuardedSalt(p.Salt, p.Sender, p.ChainID)
+	}
+	return p.Salt, nil
+}
+
+// Address returns the address the contract will be deployed at.
+func (p *Create2Params) Address() (common.Address, error) {
+	salt, err := p.EffectiveSalt()
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.CreateAddress2(p.Factory, salt, p.InitCodeHash.Bytes()), nil
+}
+
+// CreateXGuardedSalt applies CreateX's salt guard. The first 20 bytes of
+// salt select permissioned deployment (the sender's address) and byte 21
+// selects cross-chain redeploy protection (0x01), which mixes in the chain
+// ID. A salt that starts with an address and a valid flag looks
+// permissioned, so the sender is required to tell whether it is.
+func CreateXGuardedSalt(salt common.Hash, sender common.Address, chainID *big.Int) (common.Hash, error) {
+	prefix := common.BytesToAddress(salt[:common.AddressLength])
+	flag := salt[common.AddressLength]
+	if sender == (common.Address{}) && prefix != (common.Address{}) && flag <= 0x01 {
+		return common.Hash{}, fmt.Errorf("sender is required for CreateX salts that start with an address")
+	}
+	permissioned := prefix == sender && sender != (common.Address{})
+
+	needsChainID := flag == 0x01 && (permissioned || prefix == common.Address{})
+	if needsChainID && chainID == nil {
+		return common.Hash{}, fmt.Errorf("chain ID is required for salts with cross-chain redeploy protection")
+	}
+
+	switch {
+	case permissioned && flag == 0x01:
+		return crypto.Keccak256Hash(common.LeftPadBytes(sender.Bytes(), 32), common.BigToHash(chainID).Bytes(), salt.Bytes()), nil
+	case permissioned && flag == 0x00:
+		return crypto.Keccak256Hash(common.LeftPadBytes(sender.Bytes(), 32), salt.Bytes()), nil
+	case permissioned:
+		return common.Hash{}, fmt.Errorf("invalid CreateX salt: redeploy protection flag must be 0x00 or 0x01")
+	case prefix == common.Address{} && flag == 0x01:
+		return crypto.Keccak256Hash(common.BigToHash(chainID).Bytes(), salt.Bytes()), nil
+	case prefix == common.Address{} && flag != 0x00:
+		return common.Hash{}, fmt.Errorf("invalid CreateX salt: redeploy protection flag must be 0x00 or 0x01")
+	default:
+		return crypto.Keccak256Hash(salt.Bytes()), nil
+	}
+}
+
+// SaltResult is the salt found by MineSalt.
+type SaltResult struct {
+	Salt     common.Hash
+	Address  common.Address
+	Attempts uint64
+	Duration time.Duration
+}
+
+// MineSalt searches on workers goroutines for a salt whose deployment address
+// matches pattern, or until ctx is cancelled. Candidates keep the first 24
+// bytes of params.Salt, so CreateX sender and flag bytes are preserved, and
+// count upwards in the last 8. Every attempt is added to attempts, which
+// callers may read concurrently to report progress.
+func MineSalt(ctx context.Context, params Create2Params, pattern *VanityPattern, workers int, attempts *atomic.Uint64) (*SaltResult, error) {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if attempts == nil {
+		attempts = new(atomic.Uint64)
+	}
+	// Validate the salt layout once up front rather than in every worker
+	if _, err := params.EffectiveSalt(); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	const batch = 1024
+	var (
+		once  sync.Once
+		found *SaltResult
+		wg    sync.WaitGroup
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(counter uint64) {
+			defer wg.Done()
+			candidate := params
+			for ctx.Err() == nil {
+				for i := 0; i < batch; i++ {
+					binary.BigEndian.PutUint64(candidate.Salt[24:], counter)
+					counter += uint64(workers)
+
+					address, _ := candidate.Address()
+					if pattern.Matches(address) {
+						attempts.Add(uint64(i + 1))
+						once.Do(func() { found = &SaltResult{Salt: candidate.Salt, Address: address} })
+						cancel()
+						return
+					}
+				}
+				attempts.Add(batch)
+			}
+		}(uint64(w))
+	}
+	wg.Wait()
+
+	if found == nil {
+		return nil, context.Cause(ctx)
+	}
+	found.Attempts = attempts.Load()
+	found.Duration = time.Since(start)
+	return found, nil
+}
+
+// saltResultMsg carries the outcome of a salt mining search.
+type saltResultMsg struct {
+	search *searchProgress
+	params Create2Params
+	result *SaltResult
+	err    error
+}
+
+func init() {
+	registerTool(160, "Contract Address Calculator (CREATE/CREATE2)", newCreate2Screen)
+}
+
+// create2FormFields are the labels of the fields on the contract address
+// form.
+var create2FormFields = []string{
+	"Deployer (address, proxy or createx)",
+	"Nonce (CREATE only)",
+	"Salt (bytes32 hex)",
+	"Init Code or Init Code Hash (hex/@file)",
+	"CreateX Sender (optional)",
+	"CreateX Chain ID (optional)",
+	"Mine Salt: Address Prefix (hex)",
+	"Mine Salt: Address Suffix (hex)",
+	"Mine Salt: Address Regex (optional)",
+	"Mine Salt: Case Sensitive EIP-55 (y/n)",
+}
+
+const (
+	create2FieldDeployer = iota
+	create2FieldNonce
+	create2FieldSalt
+	create2FieldInitCode
+	create2FieldSender
+	create2FieldChainID
+	create2FieldMinePrefix
+	create2FieldMineSuffix
+	create2FieldMineRegex
+	create2FieldMineCaseSensitive
+)
+
+// newCreate2Form returns the initial values of the contract address form.
+func newCreate2Form() []string {
+	values := make([]string, len(create2FormFields))
+	values[create2FieldDeployer] = "proxy"
+	values[create2FieldMineCaseSensitive] = "n"
+	return values
+}
+
+// create2ParamsFromForm builds the CREATE2 parameters entered on the form.
+func create2ParamsFromForm(values []string) (*Create2Params, error) {
+	factory, err := ParseDeployer(values[create2FieldDeployer])
+	if err != nil {
+		return nil, err
+	}
+	params := &Create2Params{Factory: factory}
+
+	if salt := strings.TrimSpace(values[create2FieldSalt]); salt != "" {
+		saltBytes, err := hexutil.Decode(salt)
+		if err != nil || len(saltBytes) > common.HashLength {
+			return nil, fmt.Errorf("salt must be at most 32 bytes of 0x-prefixed hex")
+		}
+		params.Salt = common.BytesToHash(saltBytes)
+	}
+
+	initCode, err := readInputOrFile(values[create2FieldInitCode])
+	if err != nil {
+		return nil, err
+	}
+	if initCode == "" {
+		return nil, fmt.Errorf("init code or init code hash is required for CREATE2")
+	}
+	if params.InitCodeHash, err = ParseInitCodeHash(initCode); err != nil {
+		return nil, err
+	}
+
+	if sender := strings.TrimSpace(values[create2FieldSender]); sender != "" {
+		if !common.IsHexAddress(sender) {
+			return nil, fmt.Errorf("invalid CreateX sender address")
+		}
+		params.Sender = common.HexToAddress(sender)
+	}
+	if chainID := strings.TrimSpace(values[create2FieldChainID]); chainID != "" {
+		id, ok := new(big.Int).SetString(chainID, 10)
+		if !ok || id.Sign() <= 0 {
+			return nil, fmt.Errorf("invalid chain ID")
+		}
+		params.ChainID = id
+	}
+	return params, nil
+}
+
+// create2Screen calculates CREATE and CREATE2 addresses and mines salts.
+type create2Screen struct {
+	session *session
+	form    form
+	search  *searchProgress
+	content string
+}
+
+func newCreate2Screen(s *session) Screen {
+	return create2Screen{session: s, form: newForm(create2FormFields, newCreate2Form())}
+}
+
+func (c create2Screen) Init() tea.Cmd {
+	return nil
+}
+
+func (c create2Screen) Update(msg tea.Msg) (Screen, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.Type {
+		case tea.KeyCtrlC, tea.KeyEsc:
+			if c.search != nil {
+				// Cancel the running search but stay on the form
+				c.search.cancel()
+				c.search = nil
+				c.content = "Search cancelled."
+				return c, nil
+			}
+			return nil, nil
+		case tea.KeyEnter:
+			if c.search != nil {
+				return c, nil
+			}
+			return c.enter()
+		default:
+			if c.search == nil {
+				c.form = c.form.update(msg)
+			}
+		}
+	case searchTickMsg:
+		if c.search != nil {
+			return c, searchTick()
+		}
+	case saltResultMsg:
+		if c.search == nil || c.search != msg.search {
+			// Result of a search that was already cancelled
+			return c, nil
+		}
+		c.search.cancel()
+		c.search = nil
+		if msg.err != nil {
+			c.content = fmt.Sprintf("Error: %v", msg.err)
+			return c, nil
+		}
+		msg.params.Salt = msg.result.Salt
+		result := NewCreate2AddressResult(&msg.params, msg.result.Address)
+		result.Attempts = msg.result.Attempts
+		result.Duration = msg.result.Duration.Round(time.Millisecond).String()
+		return c.session.showResult(result), nil
+	}
+	return c, nil
+}
+
+func (c create2Screen) enter() (Screen, tea.Cmd) {
+	if nonce := strings.TrimSpace(c.form.values[create2FieldNonce]); nonce != "" {
+		deployer, err := ParseDeployer(c.form.values[create2FieldDeployer])
+		if err != nil {
+			c.content = fmt.Sprintf("Error: %v", err)
+			return c, nil
+		}
+		n, err := strconv.ParseUint(nonce, 10, 64)
+		if err != nil {
+			c.content = "Error: Invalid nonce."
+			return c, nil
+		}
+		return c.session.showResult(&ContractAddressResult{
+			Opcode:   "CREATE",
+			Deployer: deployer.Hex(),
+			Nonce:    &n,
+			Address:  crypto.CreateAddress(deployer, n).Hex(),
+		}), nil
+	}
+
+	params, err := create2ParamsFromForm(c.form.values)
+	if err != nil {
+		c.content = fmt.Sprintf("Error: %v", err)
+		return c, nil
+	}
+
+	prefix := strings.TrimSpace(c.form.values[create2FieldMinePrefix])
+	suffix := strings.TrimSpace(c.form.values[create2FieldMineSuffix])
+	regex := strings.TrimSpace(c.form.values[create2FieldMineRegex])
+	if prefix == "" && suffix == "" && regex == "" {
+		address, err := params.Address()
+		if err != nil {
+			c.content = fmt.Sprintf("Error: %v", err)
+			return c, nil
+		}
+		return c.session.showResult(NewCreate2AddressResult(params, address)), nil
+	}
+
+	caseSensitive := strings.EqualFold(strings.TrimSpace(c.form.values[create2FieldMineCaseSensitive]), "y")
+	pattern, err := NewVanityPattern(prefix, suffix, regex, caseSensitive)
+	if err != nil {
+		c.content = fmt.Sprintf("Error: %v", err)
+		return c, nil
+	}
+	if _, err := params.EffectiveSalt(); err != nil {
+		c.content = fmt.Sprintf("Error: %v", err)
+		return c, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	search := &searchProgress{
+		cancel:     cancel,
+		attempts:   new(atomic.Uint64),
+		start:      time.Now(),
+		difficulty: pattern.Difficulty(),
+		workers:    runtime.NumCPU(),
+	}
+	c.search = search
+	c.content = ""
+	return c, tea.Batch(searchTick(), func() tea.Msg {
+		result, err := MineSalt(ctx, *params, pattern, search.workers, search.attempts)
+		return saltResultMsg{search: search, params: *params, result: result, err: err}
+	})
+}
+
+func (c create2Screen) View() string {
+	s := titleStyle.Render("Contract Address Calculator (CREATE/CREATE2)") + "\n\n"
+	if c.search == nil {
+		s += "Enter a nonce for CREATE, or a salt and init code for CREATE2. Fill in a prefix,\n"
+		s += "suffix or regex to mine a salt. Tab/Shift+Tab to move, Enter to calculate or Esc to cancel:\n\n"
+		s += c.form.view()
+		if c.content != "" {
+			s += "\n" + c.content
+		}
+		return s
+	}
+
+	s += c.form.view() + "\n"
+	s += formatSearchProgress(c.search)
+	s += "\nMining salt... press Esc to cancel."
+	return s
+}
+
+// ContractAddressResult is the address of a contract deployed with CREATE
+// from Deployer and Nonce, or with CREATE2 from Factory, Salt and
+// InitCodeHash. Attempts and Duration are only set for mined salts.
+type ContractAddressResult struct {
+	Opcode       string  `json:"opcode" yaml:"opcode"`
+	Deployer     string  `json:"deployer,omitempty" yaml:"deployer,omitempty"`
+	Nonce        *uint64 `json:"nonce,omitempty" yaml:"nonce,omitempty"`
+	Factory      string  `json:"factory,omitempty" yaml:"factory,omitempty"`
+	FactoryName  string  `json:"factoryName,omitempty" yaml:"factoryName,omitempty"`
+	Salt         string  `json:"salt,omitempty" yaml:"salt,omitempty"`
+	GuardedSalt  string  `json:"guardedSalt,omitempty" yaml:"guardedSalt,omitempty"`
+	InitCodeHash string  `json:"initCodeHash,omitempty" yaml:"initCodeHash,omitempty"`
+	Address      string  `json:"address" yaml:"address"`
+	Attempts     uint64  `json:"attempts,omitempty" yaml:"attempts,omitempty"`
+	Duration     string  `json:"duration,omitempty" yaml:"duration,omitempty"`
+}
+
+// NewCreate2AddressResult describes the CREATE2 deployment of params at
+// address.
+func NewCreate2AddressResult(params *Create2Params, address common.Address) *ContractAddressResult {
+	r := &ContractAddressResult{
+		Opcode:       "CREATE2",
+		Factory:      params.Factory.Hex(),
+		Salt:         params.Salt.Hex(),
+		InitCodeHash: params.InitCodeHash.Hex(),
+		Address:      address.Hex(),
+	}
+	switch params.Factory {
+	case DeterministicDeploymentProxy:
+		r.FactoryName = "deterministic deployment proxy"
+	case CreateXFactory:
+		r.FactoryName = "CreateX"
+	}
+	if salt, err := params.EffectiveSalt(); err == nil && salt != params.Salt {
+		r.GuardedSalt = salt.Hex()
+	}
+	return r
+}
+
+// Text implements Result.
+func (r *ContractAddressResult) Text() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Opcode          : %s\n", r.Opcode))
+	if r.Nonce != nil {
+		sb.WriteString(fmt.Sprintf("Deployer        : %s\n", r.Deployer))
+		sb.WriteString(fmt.Sprintf("Nonce           : %d\n", *r.Nonce))
+		sb.WriteString(fmt.Sprintf("Contract Address: %s\n", r.Address))
+		return sb.String()
+	}
+	factory := r.Factory
+	if r.FactoryName != "" {
+		factory += " (" + r.FactoryName + ")"
+	}
+	sb.WriteString(fmt.Sprintf("Factory         : %s\n", factory))
+	sb.WriteString(fmt.Sprintf("Salt            : %s\n", r.Salt))
+	if r.GuardedSalt != "" {
+		sb.WriteString(fmt.Sprintf("Guarded Salt    : %s\n", r.GuardedSalt))
+	}
+	sb.WriteString(fmt.Sprintf("Init Code Hash  : %s\n", r.InitCodeHash))
+	sb.WriteString(fmt.Sprintf("Contract Address: %s\n", r.Address))
+	if r.Attempts > 0 {
+		sb.WriteString(fmt.Sprintf("\nMined in %d attempts (%s)", r.Attempts, r.Duration))
+	}
+	return sb.String()
+}
+
+// CopyFields implements Copyable.
+func (r *ContractAddressResult) CopyFields() []CopyField {
+	fields := []CopyField{{Label: "Address", Value: r.Address}}
+	if r.Salt != "" {
+		fields = append(fields, CopyField{Label: "Salt", Value: r.Salt})
+	}
+	return fields
+}
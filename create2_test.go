@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestCreate2Address(t *testing.T) {
+	// Examples from EIP-1014
+	tests := []struct {
+		factory, salt, initCode, want string
+	}{
+		{"0x0000000000000000000000000000000000000000", "0x00", "0x00", "0x4D1A2e2bB4F88F0250f26Ffff098B0b30B26BF38"},
+		{"0xdeadbeef00000000000000000000000000000000", "0x00", "0x00", "0xB928f69Bb1D91Cd65274e3c79d8986362984fDA3"},
+		{"0x00000000000000000000000000000000deadbeef", "0xcafebabe", "0xdeadbeef", "0x60f3f640a8508fC6a86d45DF051962668E1e8AC7"},
+	}
+	for _, tt := range tests {
+		values := newCreate2Form()
+		values[create2FieldDeployer] = tt.factory
+		values[create2FieldSalt] = tt.salt
+		values[create2FieldInitCode] = tt.initCode
+		params, err := create2ParamsFromForm(values)
+		if err != nil {
+			t.Fatalf("create2ParamsFromForm failed: %v", err)
+		}
+		address, err := params.Address()
+		if err != nil {
+			t.Fatalf("Address failed: %v", err)
+		}
+		if address.Hex() != tt.want {
+			t.Errorf("Expected %s, got %s", tt.want, address.Hex())
+		}
+	}
+}
+
+func TestParseDeployer(t *testing.T) {
+	if address, err := ParseDeployer("Proxy"); err != nil || address != DeterministicDeploymentProxy {
+		t.Errorf("Expected the deterministic deployment proxy, got %s (%v)", address.Hex(), err)
+	}
+	if address, err := ParseDeployer("createx"); err != nil || address != CreateXFactory {
+		t.Errorf("Expected CreateX, got %s (%v)", address.Hex(), err)
+	}
+	if _, err := ParseDeployer("factory"); err == nil {
+		t.Error("Expected an error for an unknown preset")
+	}
+}
+
+func TestParseInitCodeHash(t *testing.T) {
+	hash := crypto.Keccak256Hash([]byte{0x60, 0x00})
+	got, err := ParseInitCodeHash(hash.Hex())
+	if err != nil || got != hash {
+		t.Errorf("Expected a 32-byte value to be used as the hash, got %s (%v)", got.Hex(), err)
+	}
+	got, err = ParseInitCodeHash("6000")
+	if err != nil || got != hash {
+		t.Errorf("Expected init code to be hashed, got %s (%v)", got.Hex(), err)
+	}
+}
+
+func TestCreateXGuardedSalt(t *testing.T) {
+	sender := common.HexToAddress("0x2c7536E3605D9C16a7a3D7b1898e529396a65c23")
+	chainID := big.NewInt(1)
+
+	salt := func(prefix common.Address, flag byte) common.Hash {
+		var s common.Hash
+		copy(s[:], prefix[:])
+		s[common.AddressLength] = flag
+		s[31] = 0x2a
+		return s
+	}
+
+	permissioned := salt(sender, 0x00)
+	guarded, err := CreateXGuardedSalt(permissioned, sender, nil)
+	if err != nil {
+		t.Fatalf("CreateXGuardedSalt failed: %v", err)
+	}
+	if want := crypto.Keccak256Hash(common.LeftPadBytes(sender.Bytes(), 32), permissioned.Bytes()); guarded != want {
+		t.Errorf("Permissioned salt: expected %s, got %s", want.Hex(), guarded.Hex())
+	}
+
+	crossChain := salt(common.Address{}, 0x01)
+	if _, err := CreateXGuardedSalt(crossChain, sender, nil); err == nil {
+		t.Error("Expected an error when the chain ID is missing")
+	}
+	guarded, err = CreateXGuardedSalt(crossChain, sender, chainID)
+	if err != nil {
+		t.Fatalf("CreateXGuardedSalt failed: %v", err)
+	}
+	if want := crypto.Keccak256Hash(common.BigToHash(chainID).Bytes(), crossChain.Bytes()); guarded != want {
+		t.Errorf("Cross-chain salt: expected %s, got %s", want.Hex(), guarded.Hex())
+	}
+
+	if _, err := CreateXGuardedSalt(salt(sender, 0x02), sender, chainID); err == nil {
+		t.Error("Expected an error for an invalid redeploy protection flag")
+	}
+
+	// Without a sender, a salt starting with an address cannot be guarded
+	if _, err := CreateXGuardedSalt(permissioned, common.Address{}, chainID); err == nil {
+		t.Error("Expected an error when the sender of a permissioned salt is missing")
+	}
+
+	// Salts for a different sender are treated as random
+	random := salt(common.HexToAddress("0x0000000000000000000000000000000000000001"), 0x01)
+	guarded, err = CreateXGuardedSalt(random, sender, nil)
+	if err != nil {
+		t.Fatalf("CreateXGuardedSalt failed: %v", err)
+	}
+	if want := crypto.Keccak256Hash(random.Bytes()); guarded != want {
+		t.Errorf("Random salt: expected %s, got %s", want.Hex(), guarded.Hex())
+	}
+}
+
+func TestMineSalt(t *testing.T) {
+	params := Create2Params{
+		Factory:      DeterministicDeploymentProxy,
+		Salt:         common.HexToHash("0x1111111111111111111111111111111111111111111111110000000000000000"),
+		InitCodeHash: crypto.Keccak256Hash([]byte{0x00}),
+	}
+	pattern, err := NewVanityPattern("00", "", "", false)
+	if err != nil {
+		t.Fatalf("NewVanityPattern failed: %v", err)
+	}
+	result, err := MineSalt(context.Background(), params, pattern, 2, nil)
+	if err != nil {
+		t.Fatalf("MineSalt failed: %v", err)
+	}
+	if !strings.HasPrefix(result.Address.Hex(), "0x00") {
+		t.Errorf("Address %s does not match the pattern", result.Address.Hex())
+	}
+	if !bytes.Equal(result.Salt[:24], params.Salt[:24]) {
+		t.Errorf("Mined salt %s does not keep the first 24 bytes", result.Salt.Hex())
+	}
+	params.Salt = result.Salt
+	if address, _ := params.Address(); address != result.Address {
+		t.Errorf("Mined salt gives %s, expected %s", address.Hex(), result.Address.Hex())
+	}
+}
+
+func TestCreate2ScreenMineRegex(t *testing.T) {
+	c := newCreate2Screen(&session{}).(create2Screen)
+	c.form.values[create2FieldSalt] = "0x00"
+	c.form.values[create2FieldInitCode] = "0x00"
+	c.form.values[create2FieldMineRegex] = "["
+	screen, _ := c.enter()
+	if got := screen.(create2Screen); got.search != nil || !strings.HasPrefix(got.content, "Error:") {
+		t.Fatalf("Expected an error for an invalid regex, got %q", got.content)
+	}
+
+	c.form.values[create2FieldMineRegex] = "00"
+	c.form.values[create2FieldMineCaseSensitive] = "y"
+	screen, cmd := c.enter()
+	got := screen.(create2Screen)
+	if got.search == nil || cmd == nil {
+		t.Fatalf("Expected a regex to start mining, got %q", got.content)
+	}
+	got.search.cancel()
+}
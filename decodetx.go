@@ -0,0 +1,317 @@
+// decodetx.go
+
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// DecodedTransaction is a raw signed transaction together with its
+// recovered sender.
+type DecodedTransaction struct {
+	Tx   *types.Transaction
+	From common.Address
+}
+
+// DecodeRawTransaction decodes a hex-encoded signed transaction of any
+// envelope type and recovers its sender.
+func DecodeRawTransaction(rawHex string) (*DecodedTransaction, error) {
+	rawHex = strings.TrimSpace(rawHex)
+	if !strings.HasPrefix(rawHex, "0x") {
+		rawHex = "0x" + rawHex
+	}
+	raw, err := hexutil.Decode(rawHex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hex: %v", err)
+	}
+
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return nil, fmt.Errorf("failed to decode transaction: %v", err)
+	}
+
+	// Pre-EIP-155 legacy transactions are not bound to a chain
+	var signer types.Signer = types.HomesteadSigner{}
+	if tx.Protected() {
+		signer = types.LatestSignerForChainID(tx.ChainId())
+	}
+	from, err := types.Sender(signer, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to recover sender: %v", err)
+	}
+
+	return &DecodedTransaction{Tx: tx, From: from}, nil
+}
+
+// DecodedTxResult describes a decoded transaction. Amounts are in wei and
+// fields that do not apply to the transaction type are left out.
+type DecodedTxResult struct {
+	Type                 string                 `json:"type" yaml:"type"`
+	Hash                 string                 `json:"hash" yaml:"hash"`
+	From                 string                 `json:"from" yaml:"from"`
+	ChainID              string                 `json:"chainId,omitempty" yaml:"chainId,omitempty"`
+	Nonce                uint64                 `json:"nonce" yaml:"nonce"`
+	To                   string                 `json:"to,omitempty" yaml:"to,omitempty"`
+	ContractAddress      string                 `json:"contractAddress,omitempty" yaml:"contractAddress,omitempty"`
+	Value                string                 `json:"value" yaml:"value"`
+	Gas                  uint64                 `json:"gas" yaml:"gas"`
+	GasPrice             string                 `json:"gasPrice,omitempty" yaml:"gasPrice,omitempty"`
+	MaxFeePerGas         string                 `json:"maxFeePerGas,omitempty" yaml:"maxFeePerGas,omitempty"`
+	MaxPriorityFeePerGas string                 `json:"maxPriorityFeePerGas,omitempty" yaml:"maxPriorityFeePerGas,omitempty"`
+	MaxCost              string                 `json:"maxCost" yaml:"maxCost"`
+	MaxFeePerBlobGas     string                 `json:"maxFeePerBlobGas,omitempty" yaml:"maxFeePerBlobGas,omitempty"`
+	BlobGas              uint64                 `json:"blobGas,omitempty" yaml:"blobGas,omitempty"`
+	BlobHashes           []string               `json:"blobHashes,omitempty" yaml:"blobHashes,omitempty"`
+	Blobs                int                    `json:"blobs,omitempty" yaml:"blobs,omitempty"`
+	AccessList           []DecodedAccessTuple   `json:"accessList,omitempty" yaml:"accessList,omitempty"`
+	Authorizations       []DecodedAuthorization `json:"authorizationList,omitempty" yaml:"authorizationList,omitempty"`
+	Selector             string                 `json:"selector,omitempty" yaml:"selector,omitempty"`
+	Data                 string                 `json:"data" yaml:"data"`
+	V                    string                 `json:"v" yaml:"v"`
+	R                    string                 `json:"r" yaml:"r"`
+	S                    string                 `json:"s" yaml:"s"`
+}
+
+// DecodedAccessTuple is an entry of an access list.
+type DecodedAccessTuple struct {
+	Address     string   `json:"address" yaml:"address"`
+	StorageKeys []string `json:"storageKeys" yaml:"storageKeys"`
+}
+
+// DecodedAuthorization is an EIP-7702 authorization. Authority is empty when
+// it cannot be recovered.
+type DecodedAuthorization struct {
+	Authority string `json:"authority,omitempty" yaml:"authority,omitempty"`
+	Address   string `json:"address" yaml:"address"`
+	ChainID   string `json:"chainId" yaml:"chainId"`
+	Nonce     uint64 `json:"nonce" yaml:"nonce"`
+}
+
+// NewDecodedTxResult converts a decoded transaction.
+func NewDecodedTxResult(decoded *DecodedTransaction) *DecodedTxResult {
+	tx := decoded.Tx
+	r := &DecodedTxResult{
+		Type:    txTypeName(tx.Type()),
+		Hash:    tx.Hash().Hex(),
+		From:    decoded.From.Hex(),
+		Nonce:   tx.Nonce(),
+		Value:   tx.Value().String(),
+		Gas:     tx.Gas(),
+		MaxCost: tx.Cost().String(),
+		Data:    hexutil.Encode(tx.Data()),
+	}
+	if tx.Protected() {
+		r.ChainID = tx.ChainId().String()
+	}
+	if to := tx.To(); to != nil {
+		r.To = to.Hex()
+	} else {
+		r.ContractAddress = crypto.CreateAddress(decoded.From, tx.Nonce()).Hex()
+	}
+
+	switch tx.Type() {
+	case types.LegacyTxType, types.AccessListTxType:
+		r.GasPrice = tx.GasPrice().String()
+	default:
+		r.MaxFeePerGas = tx.GasFeeCap().String()
+		r.MaxPriorityFeePerGas = tx.GasTipCap().String()
+	}
+
+	if tx.Type() == types.BlobTxType {
+		r.MaxFeePerBlobGas = tx.BlobGasFeeCap().String()
+		r.BlobGas = tx.BlobGas()
+		r.BlobHashes = []string{}
+		for _, hash := range tx.BlobHashes() {
+			r.BlobHashes = append(r.BlobHashes, hash.Hex())
+		}
+		if sidecar := tx.BlobTxSidecar(); sidecar != nil {
+			r.Blobs = len(sidecar.Blobs)
+		}
+	}
+
+	for _, tuple := range tx.AccessList() {
+		entry := DecodedAccessTuple{Address: tuple.Address.Hex(), StorageKeys: []string{}}
+		for _, key := range tuple.StorageKeys {
+			entry.StorageKeys = append(entry.StorageKeys, key.Hex())
+		}
+		r.AccessList = append(r.AccessList, entry)
+	}
+
+	for _, auth := range tx.SetCodeAuthorizations() {
+		entry := DecodedAuthorization{Address: auth.Address.Hex(), ChainID: auth.ChainID.Dec(), Nonce: auth.Nonce}
+		if address, err := auth.Authority(); err == nil {
+			entry.Authority = address.Hex()
+		}
+		r.Authorizations = append(r.Authorizations, entry)
+	}
+
+	if data := tx.Data(); len(data) >= 4 && tx.To() != nil {
+		r.Selector = hexutil.Encode(data[:4])
+	}
+
+	v, rr, ss := tx.RawSignatureValues()
+	r.V = v.String()
+	r.R = common.BigToHash(rr).Hex()
+	r.S = common.BigToHash(ss).Hex()
+	return r
+}
+
+// formatWei formats a decimal wei amount in the given unit.
+func formatWei(wei, unit string) string {
+	amount, ok := new(big.Int).SetString(wei, 10)
+	if !ok {
+		return wei + " wei"
+	}
+	return FormatAmount(amount, unit)
+}
+
+// Text implements Result.
+func (r *DecodedTxResult) Text() string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("Type                : %s\n", r.Type))
+	sb.WriteString(fmt.Sprintf("Tx Hash             : %s\n", r.Hash))
+	sb.WriteString(fmt.Sprintf("From                : %s\n", r.From))
+	if r.ChainID != "" {
+		sb.WriteString(fmt.Sprintf("Chain ID            : %s\n", r.ChainID))
+	} else {
+		sb.WriteString("Chain ID            : none (pre-EIP-155)\n")
+	}
+	sb.WriteString(fmt.Sprintf("Nonce               : %d\n", r.Nonce))
+	if r.To != "" {
+		sb.WriteString(fmt.Sprintf("To                  : %s\n", r.To))
+	} else {
+		sb.WriteString(fmt.Sprintf("To                  : contract creation (%s)\n", r.ContractAddress))
+	}
+	sb.WriteString(fmt.Sprintf("Value               : %s (%s wei)\n", formatWei(r.Value, "ether"), r.Value))
+	sb.WriteString(fmt.Sprintf("Gas Limit           : %d\n", r.Gas))
+
+	if r.GasPrice != "" {
+		sb.WriteString(fmt.Sprintf("Gas Price           : %s\n", formatWei(r.GasPrice, "gwei")))
+	} else {
+		sb.WriteString(fmt.Sprintf("Max Fee Per Gas     : %s\n", formatWei(r.MaxFeePerGas, "gwei")))
+		sb.WriteString(fmt.Sprintf("Max Priority Fee    : %s\n", formatWei(r.MaxPriorityFeePerGas, "gwei")))
+	}
+	sb.WriteString(fmt.Sprintf("Max Cost            : %s\n", formatWei(r.MaxCost, "ether")))
+
+	if r.MaxFeePerBlobGas != "" {
+		sb.WriteString(fmt.Sprintf("Max Fee Per Blob Gas: %s\n", formatWei(r.MaxFeePerBlobGas, "gwei")))
+		sb.WriteString(fmt.Sprintf("Blob Gas            : %d\n", r.BlobGas))
+		sb.WriteString("Blob Versioned Hashes:\n")
+		for _, hash := range r.BlobHashes {
+			sb.WriteString(fmt.Sprintf("  - %s\n", hash))
+		}
+		if r.Blobs > 0 {
+			sb.WriteString(fmt.Sprintf("Blob Sidecar        : %d blobs included\n", r.Blobs))
+		}
+	}
+
+	if len(r.AccessList) > 0 {
+		sb.WriteString("Access List:\n")
+		for _, tuple := range r.AccessList {
+			sb.WriteString(fmt.Sprintf("  - %s\n", tuple.Address))
+			for _, key := range tuple.StorageKeys {
+				sb.WriteString(fmt.Sprintf("      %s\n", key))
+			}
+		}
+	}
+
+	if len(r.Authorizations) > 0 {
+		sb.WriteString("Authorization List:\n")
+		for i, auth := range r.Authorizations {
+			authority := auth.Authority
+			if authority == "" {
+				authority = "unrecoverable"
+			}
+			sb.WriteString(fmt.Sprintf("  %d. Authority %s delegates to %s\n", i+1, authority, auth.Address))
+			sb.WriteString(fmt.Sprintf("     Chain ID %s, Nonce %d\n", auth.ChainID, auth.Nonce))
+		}
+	}
+
+	if r.Selector != "" {
+		sb.WriteString(fmt.Sprintf("Function Selector   : %s\n", r.Selector))
+	}
+	sb.WriteString(fmt.Sprintf("Data                : %s (%d bytes)\n", r.Data, (len(r.Data)-2)/2))
+
+	sb.WriteString(fmt.Sprintf("Signature v         : %s\n", r.V))
+	sb.WriteString(fmt.Sprintf("Signature r         : %s\n", r.R))
+	sb.WriteString(fmt.Sprintf("Signature s         : %s\n", r.S))
+
+	return sb.String()
+}
+
+// CopyFields implements Copyable.
+func (r *DecodedTxResult) CopyFields() []CopyField {
+	return []CopyField{
+		{Label: "Tx Hash", Value: r.Hash},
+		{Label: "From", Value: r.From},
+		{Label: "Data", Value: r.Data},
+	}
+}
+
+func init() {
+	registerTool(150, "Decode Raw Transaction", newDecodeTxScreen)
+}
+
+// decodeTxScreen decodes a raw signed transaction.
+type decodeTxScreen struct {
+	session *session
+	raw     textInput
+	content string
+}
+
+func newDecodeTxScreen(s *session) Screen {
+	return decodeTxScreen{session: s, raw: newTextInput()}
+}
+
+func (d decodeTxScreen) Init() tea.Cmd {
+	return nil
+}
+
+func (d decodeTxScreen) Update(msg tea.Msg) (Screen, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.Type {
+		case tea.KeyCtrlC, tea.KeyEsc:
+			return nil, nil
+		case tea.KeyEnter:
+			if d.raw.Value() == "" {
+				d.content = "Error: Raw transaction cannot be empty."
+				return d, nil
+			}
+			rawHex, err := readInputOrFile(d.raw.Value())
+			if err != nil {
+				d.content = fmt.Sprintf("Error: %v", err)
+				return d, nil
+			}
+			decoded, err := DecodeRawTransaction(rawHex)
+			if err != nil {
+				d.content = fmt.Sprintf("Error decoding transaction: %v", err)
+				return d, nil
+			}
+			return d.session.showResult(NewDecodedTxResult(decoded)), nil
+		default:
+			d.raw = d.raw.Update(msg)
+		}
+	}
+	return d, nil
+}
+
+func (d decodeTxScreen) View() string {
+	s := titleStyle.Render("Decode Raw Transaction") + "\n\n"
+	s += "Enter the raw signed transaction (in hex format, or @path/to/file) or press Esc to cancel:\n"
+	s += d.raw.View()
+	if d.content != "" {
+		s += "\n\n" + d.content + "\n\nPress Enter to continue..."
+	}
+	return s
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/holiman/uint256"
+)
+
+func TestDecodeRawTransactionLegacy(t *testing.T) {
+	// Signed example transaction from the EIP-155 specification
+	raw := "0xf86c098504a817c800825208943535353535353535353535353535353535353535880de0b6b3a76400008025a028ef61340bd939bc2195fe537567866003e1a15d3c71ff63e1590620aa636276a067cbe9d8997f761aecb703304b3800ccf555c9f3dc64214b297fb1966a3b6d83"
+
+	decoded, err := DecodeRawTransaction(raw)
+	if err != nil {
+		t.Fatalf("Failed to decode transaction: %v", err)
+	}
+	if decoded.From != common.HexToAddress("0x9d8A62f656a8d1615C1294fd71e9CFb3E4855A4F") {
+		t.Errorf("Sender mismatch, got %s", decoded.From.Hex())
+	}
+	if decoded.Tx.Nonce() != 9 || decoded.Tx.ChainId().Int64() != 1 {
+		t.Errorf("Decoded fields mismatch: nonce %d, chain ID %s", decoded.Tx.Nonce(), decoded.Tx.ChainId())
+	}
+
+	output := NewDecodedTxResult(decoded).Text()
+	if !strings.Contains(output, "Value               : 1 ether") {
+		t.Errorf("Formatted output is missing the value:\n%s", output)
+	}
+}
+
+func TestDecodeRawTransactionBlobAndSetCode(t *testing.T) {
+	privateKey, _ := crypto.HexToECDSA("4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318")
+	from := crypto.PubkeyToAddress(privateKey.PublicKey)
+	chainID := big.NewInt(17000)
+	signer := types.LatestSignerForChainID(chainID)
+	to := common.HexToAddress("0x3535353535353535353535353535353535353535")
+
+	auth, err := types.SignSetCode(privateKey, types.SetCodeAuthorization{
+		ChainID: *uint256.NewInt(17000),
+		Address: to,
+		Nonce:   1,
+	})
+	if err != nil {
+		t.Fatalf("Failed to sign authorization: %v", err)
+	}
+
+	txs := map[string]types.TxData{
+		"blob": &types.BlobTx{
+			ChainID:    uint256.NewInt(17000),
+			Nonce:      3,
+			GasTipCap:  uint256.NewInt(1000000000),
+			GasFeeCap:  uint256.NewInt(30000000000),
+			Gas:        21000,
+			To:         to,
+			Value:      uint256.NewInt(0),
+			BlobFeeCap: uint256.NewInt(1000000000),
+			BlobHashes: []common.Hash{common.HexToHash("0x01aa")},
+		},
+		"set code": &types.SetCodeTx{
+			ChainID:   uint256.NewInt(17000),
+			Nonce:     0,
+			GasTipCap: uint256.NewInt(1000000000),
+			GasFeeCap: uint256.NewInt(30000000000),
+			Gas:       100000,
+			To:        from,
+			Value:     uint256.NewInt(0),
+			AuthList:  []types.SetCodeAuthorization{auth},
+		},
+	}
+
+	for name, txData := range txs {
+		tx, err := types.SignNewTx(privateKey, signer, txData)
+		if err != nil {
+			t.Fatalf("%s: failed to sign transaction: %v", name, err)
+		}
+		raw, err := tx.MarshalBinary()
+		if err != nil {
+			t.Fatalf("%s: failed to encode transaction: %v", name, err)
+		}
+
+		decoded, err := DecodeRawTransaction(hexutil.Encode(raw))
+		if err != nil {
+			t.Fatalf("%s: failed to decode transaction: %v", name, err)
+		}
+		if decoded.From != from {
+			t.Errorf("%s: sender mismatch, got %s", name, decoded.From.Hex())
+		}
+		if decoded.Tx.Hash() != tx.Hash() {
+			t.Errorf("%s: hash mismatch", name)
+		}
+
+		output := NewDecodedTxResult(decoded).Text()
+		if name == "set code" && !strings.Contains(output, "Authority "+from.Hex()+" delegates to "+to.Hex()) {
+			t.Errorf("%s: formatted output is missing the authorization:\n%s", name, output)
+		}
+		if name == "blob" && !strings.Contains(output, common.HexToHash("0x01aa").Hex()) {
+			t.Errorf("%s: formatted output is missing the blob hash:\n%s", name, output)
+		}
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	cases := []struct {
+		wei      *big.Int
+		unit     string
+		expected string
+	}{
+		{big.NewInt(0), "ether", "0 ether"},
+		{big.NewInt(1500000000000000000), "ether", "1.5 ether"},
+		{big.NewInt(1), "ether", "0.000000000000000001 ether"},
+		{big.NewInt(30000000000), "gwei", "30 gwei"},
+		{big.NewInt(1000000001), "gwei", "1.000000001 gwei"},
+	}
+	for _, c := range cases {
+		if got := FormatAmount(c.wei, c.unit); got != c.expected {
+			t.Errorf("FormatAmount(%s, %s): expected %q, got %q", c.wei, c.unit, c.expected, got)
+		}
+	}
+}
@@ -0,0 +1,298 @@
+// display.go
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+var matchStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("#000000")).
+	Background(lipgloss.Color("#FFA500"))
+
+// hiddenSecret is shown in place of secrets until they are revealed.
+const hiddenSecret = "[hidden, press r to reveal]"
+
+// displayScreen shows the outcome of a tool until Enter is pressed. The
+// output is wrapped to the terminal width and scrolls when it is taller than
+// the terminal, and can be searched with /. Secrets in it are hidden until r
+// is pressed.
+type displayScreen struct {
+	screenID
+	session  *session
+	content  string
+	result   Result
+	secret   bool     // the output contains a private key or mnemonic
+	secrets  []string // values hidden until revealed
+	revealed bool
+	status   string
+	viewport viewport.Model
+
+	searching bool
+	search    textInput
+	query     string
+	matches   []int // wrapped lines that contain the query
+	match     int
+}
+
+// showResult returns a screen showing r in the selected output format.
+func (s *session) showResult(r Result) Screen {
+	d := displayScreen{screenID: newScreenID(), session: s, result: r, viewport: viewport.New(0, 0)}
+	for _, field := range d.fields() {
+		if field.Secret {
+			d.secrets = append(d.secrets, field.Value)
+		}
+	}
+	if sr, ok := r.(SecretResult); ok {
+		d.secrets = append(d.secrets, sr.Secrets()...)
+	}
+	d.secret = len(d.secrets) > 0
+	d.content = d.render()
+	return d.layout()
+}
+
+// showText returns a screen showing preformatted content.
+func (s *session) showText(content string) Screen {
+	return displayScreen{screenID: newScreenID(), session: s, content: content, viewport: viewport.New(0, 0)}.layout()
+}
+
+// wipe zeroes the secrets of the result and drops the copies made to hide
+// them. The rendered output goes with the screen once the TUI leaves it.
+func (d displayScreen) wipe() {
+	if w, ok := d.result.(interface{ wipe() }); ok {
+		w.wipe()
+	}
+	clear(d.secrets)
+}
+
+// render renders the result in the selected output format.
+func (d displayScreen) render() string {
+	s, err := RenderResult(d.result, d.session.outputFormat)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	return s
+}
+
+// footerHeight returns the number of lines shown below the output.
+func (d displayScreen) footerHeight() int {
+	if d.result != nil {
+		return 5
+	}
+	return 4
+}
+
+// visible returns the content with its secrets hidden unless they were
+// revealed.
+func (d displayScreen) visible() string {
+	content := d.content
+	if !d.revealed {
+		for _, secret := range d.secrets {
+			if secret != "" {
+				content = strings.ReplaceAll(content, secret, hiddenSecret)
+			}
+		}
+	}
+	return content
+}
+
+// layout wraps the content to the terminal, highlights search matches and
+// sizes the viewport to the space left by the footer. Until the terminal
+// size is known the whole output is shown.
+func (d displayScreen) layout() displayScreen {
+	content := d.visible()
+	if d.session.width > 0 {
+		content = ansi.Wrap(content, d.session.width, "")
+	}
+	lines := strings.Split(content, "\n")
+
+	d.matches = nil
+	if d.query != "" {
+		pattern := regexp.MustCompile("(?i)" + regexp.QuoteMeta(d.query))
+		for i, line := range lines {
+			if pattern.MatchString(line) {
+				d.matches = append(d.matches, i)
+				lines[i] = pattern.ReplaceAllStringFunc(line, func(m string) string { return matchStyle.Render(m) })
+			}
+		}
+	}
+	if d.match >= len(d.matches) {
+		d.match = 0
+	}
+
+	height := len(lines)
+	if d.session.height > 0 {
+		height = max(1, d.session.height-d.footerHeight())
+	}
+	offset := d.viewport.YOffset
+	d.viewport.Width = d.session.width
+	d.viewport.Height = height
+	d.viewport.SetContent(strings.Join(lines, "\n"))
+	d.viewport.SetYOffset(offset)
+	return d
+}
+
+// showMatch scrolls to the current search match.
+func (d displayScreen) showMatch() displayScreen {
+	if len(d.matches) > 0 {
+		d.viewport.SetYOffset(d.matches[d.match])
+	}
+	return d
+}
+
+func (d displayScreen) Init() tea.Cmd {
+	return nil
+}
+
+func (d displayScreen) Update(msg tea.Msg) (Screen, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		return d.layout(), nil
+	case tea.KeyMsg:
+		if d.searching {
+			return d.updateSearch(msg), nil
+		}
+		switch msg.String() {
+		case "enter":
+			return nil, nil
+		case "tab":
+			if d.result != nil {
+				d.session.outputFormat = d.session.outputFormat.next()
+				d.content = d.render()
+				d.viewport.GotoTop()
+				return d.layout(), nil
+			}
+		case "/":
+			d.searching = true
+			d.search = newTextInput()
+			return d, nil
+		case "n":
+			if len(d.matches) > 0 {
+				d.match = (d.match + 1) % len(d.matches)
+				return d.showMatch(), nil
+			}
+		case "N":
+			if len(d.matches) > 0 {
+				d.match = (d.match + len(d.matches) - 1) % len(d.matches)
+				return d.showMatch(), nil
+			}
+		case "esc":
+			d.query = ""
+			return d.layout(), nil
+		case "r":
+			if len(d.secrets) > 0 {
+				d.revealed = !d.revealed
+				return d.layout(), nil
+			}
+		case "c":
+			return d.copy("the output", d.content, d.secret)
+		case "home", "g":
+			d.viewport.GotoTop()
+			return d, nil
+		case "end", "G":
+			d.viewport.GotoBottom()
+			return d, nil
+		}
+		if msg.Type == tea.KeyRunes && len(msg.Runes) == 1 {
+			// 1-9 copy the fields of the result
+			if fields, i := d.fields(), int(msg.Runes[0]-'1'); i >= 0 && i < len(fields) {
+				return d.copy(fields[i].Label, fields[i].Value, fields[i].Secret)
+			}
+		}
+	}
+
+	var cmd tea.Cmd
+	d.viewport, cmd = d.viewport.Update(msg)
+	return d, cmd
+}
+
+// fields returns the fields of the result that can be copied on their own.
+func (d displayScreen) fields() []CopyField {
+	if c, ok := d.result.(Copyable); ok {
+		return c.CopyFields()
+	}
+	return nil
+}
+
+// copy copies value to the clipboard and reports it in the footer.
+func (d displayScreen) copy(label, value string, secret bool) (Screen, tea.Cmd) {
+	cmd, err := d.session.copyToClipboard(value, secret)
+	if err != nil {
+		d.status = fmt.Sprintf("Error copying %s: %v", label, err)
+		return d, nil
+	}
+	d.status = fmt.Sprintf("Copied %s to the clipboard.", label)
+	if cmd != nil {
+		d.status += fmt.Sprintf(" It will be cleared in %s.", d.session.clipboardClear)
+	}
+	return d, cmd
+}
+
+// updateSearch handles typing a search query. Enter jumps to the first
+// match and Esc leaves the previous search in place.
+func (d displayScreen) updateSearch(msg tea.KeyMsg) displayScreen {
+	switch msg.Type {
+	case tea.KeyEnter:
+		d.searching = false
+		d.query = d.search.Value()
+		d.match = 0
+		return d.layout().showMatch()
+	case tea.KeyEsc, tea.KeyCtrlC:
+		d.searching = false
+	default:
+		d.search = d.search.Update(msg)
+	}
+	return d
+}
+
+func (d displayScreen) View() string {
+	var footer []string
+	if d.result != nil {
+		footer = append(footer, fmt.Sprintf("Output: %s (press Tab to switch)", menuStyle.Render(d.session.outputFormat.String())))
+	}
+
+	switch {
+	case d.searching:
+		footer = append(footer, "Search: "+d.search.View())
+	case d.query != "" && len(d.matches) == 0:
+		footer = append(footer, fmt.Sprintf("No matches for %q", d.query))
+	case d.query != "":
+		footer = append(footer, fmt.Sprintf("Match %d of %d for %q (n/N for next/previous, Esc to clear)", d.match+1, len(d.matches), d.query))
+	default:
+		total := d.viewport.TotalLineCount()
+		last := min(d.viewport.YOffset+d.viewport.Height, total)
+		footer = append(footer, fmt.Sprintf("Lines %d-%d of %d", min(d.viewport.YOffset+1, total), last, total))
+	}
+	if d.status != "" {
+		footer = append(footer, d.status)
+	} else {
+		copyHelp := "Copy: c output"
+		for i, field := range d.fields() {
+			copyHelp += fmt.Sprintf(", %d %s", i+1, field.Label)
+		}
+		footer = append(footer, copyHelp)
+	}
+	help := "↑/↓/PgUp/PgDn to scroll, / to search, Enter to return to menu..."
+	switch {
+	case len(d.secrets) > 0 && d.revealed:
+		help = "r to hide secrets, " + help
+	case len(d.secrets) > 0:
+		help = "r to reveal secrets, " + help
+	}
+	footer = append(footer, help)
+
+	// Keep the footer to one row per line so the output keeps its place
+	if d.session.width > 0 {
+		for i, line := range footer {
+			footer[i] = ansi.Truncate(line, d.session.width, "…")
+		}
+	}
+	return d.viewport.View() + "\n\n" + strings.Join(footer, "\n")
+}
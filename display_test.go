@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func longOutput(lines int) string {
+	var sb strings.Builder
+	for i := 1; i <= lines; i++ {
+		sb.WriteString(fmt.Sprintf("line %d\n", i))
+	}
+	sb.WriteString(strings.Repeat("ab", 50))
+	return sb.String()
+}
+
+func updateDisplay(t *testing.T, screen Screen, msgs ...tea.Msg) Screen {
+	t.Helper()
+	for _, msg := range msgs {
+		screen, _ = screen.Update(msg)
+		if screen == nil {
+			t.Fatal("Display screen closed unexpectedly")
+		}
+	}
+	return screen
+}
+
+func TestDisplayScrolling(t *testing.T) {
+	s := &session{width: 40, height: 10}
+	screen := s.showText(longOutput(50))
+
+	view := screen.View()
+	lines := strings.Split(view, "\n")
+	if len(lines) != 10 {
+		t.Fatalf("Expected the view to fit 10 rows, got %d:\n%s", len(lines), view)
+	}
+	if !strings.Contains(view, "line 6") || strings.Contains(view, "line 7 ") || !strings.Contains(view, "Lines 1-6 of 53") {
+		t.Errorf("Expected the first page, got:\n%s", view)
+	}
+
+	screen = updateDisplay(t, screen, tea.KeyMsg{Type: tea.KeyPgDown})
+	if view := screen.View(); !strings.Contains(view, "line 7") || strings.Contains(view, "line 1 ") {
+		t.Errorf("Expected the second page, got:\n%s", view)
+	}
+
+	// Long lines are wrapped to the terminal width
+	screen = updateDisplay(t, screen, tea.KeyMsg{Type: tea.KeyEnd})
+	for _, line := range strings.Split(screen.View(), "\n") {
+		if w := ansi.StringWidth(line); w > 40 {
+			t.Errorf("Line wider than the terminal (%d): %q", w, line)
+		}
+	}
+
+	// A smaller terminal shows fewer lines
+	s.height = 6
+	screen = updateDisplay(t, screen, tea.WindowSizeMsg{Width: 40, Height: 6})
+	if lines := strings.Split(screen.View(), "\n"); len(lines) != 6 {
+		t.Errorf("Expected the view to shrink to 6 rows, got %d", len(lines))
+	}
+}
+
+func TestDisplaySearch(t *testing.T) {
+	s := &session{width: 40, height: 10}
+	screen := s.showText(longOutput(50))
+
+	screen = updateDisplay(t, screen, runes("/"), runes("LINE 4"), tea.KeyMsg{Type: tea.KeyEnter})
+	s.width = 80
+	screen = updateDisplay(t, screen, tea.WindowSizeMsg{Width: 80, Height: 10})
+	view := screen.View()
+	if !strings.Contains(view, `Match 1 of 11 for "LINE 4"`) || !strings.HasPrefix(view, "line 4 ") {
+		t.Fatalf("Expected to jump to the first match, got:\n%s", view)
+	}
+
+	screen = updateDisplay(t, screen, runes("N"))
+	if view := screen.View(); !strings.Contains(view, "Match 11 of 11") || !strings.Contains(view, "line 49") {
+		t.Errorf("Expected N to wrap to the last match, got:\n%s", view)
+	}
+
+	screen = updateDisplay(t, screen, tea.KeyMsg{Type: tea.KeyEsc})
+	if view := screen.View(); strings.Contains(view, "Match") {
+		t.Errorf("Expected Esc to clear the search, got:\n%s", view)
+	}
+
+	if next, _ := screen.Update(tea.KeyMsg{Type: tea.KeyEnter}); next != nil {
+		t.Error("Expected Enter to return to the menu")
+	}
+}
+
+func TestDisplayRevealSecrets(t *testing.T) {
+	s := &session{}
+	screen := s.showResult(&KeyResult{PrivateKey: Secret(cliTestKey), Address: cliTestAddress})
+	view := screen.View()
+	if strings.Contains(view, cliTestKey) || !strings.Contains(view, hiddenSecret) || !strings.Contains(view, cliTestAddress) {
+		t.Fatalf("Expected only the key to be hidden, got:\n%s", view)
+	}
+
+	screen = updateDisplay(t, screen, runes("r"))
+	if view := screen.View(); !strings.Contains(view, cliTestKey) || !strings.Contains(view, "r to hide secrets") {
+		t.Errorf("Expected r to reveal the key, got:\n%s", view)
+	}
+
+	// Other formats are hidden too until revealed
+	screen = updateDisplay(t, screen, runes("r"), tea.KeyMsg{Type: tea.KeyTab})
+	if view := screen.View(); !strings.Contains(view, `"privateKey": "`+hiddenSecret) {
+		t.Errorf("Expected the key to be hidden in JSON, got:\n%s", view)
+	}
+
+	// Secrets that are not copy fields are hidden too
+	wallet := &HDWalletResult{Accounts: []HDAccountResult{{Path: "m/0", Address: cliTestAddress, PrivateKey: Secret(cliTestKey)}}}
+	if view := s.showResult(wallet).View(); strings.Contains(view, cliTestKey) {
+		t.Errorf("Expected the derived keys to be hidden, got:\n%s", view)
+	}
+}
+
+func TestDisplayWipe(t *testing.T) {
+	result := &KeyResult{PrivateKey: Secret(cliTestKey), Address: cliTestAddress}
+	m := model{session: &session{}, screen: (&session{}).showResult(result)}
+	secrets := m.screen.(displayScreen).secrets
+	m = sendKeys(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.screen != nil {
+		t.Fatal("Expected Enter to return to the menu")
+	}
+	if strings.Trim(string(result.PrivateKey), "\x00") != "" {
+		t.Errorf("Expected the key of the result to be wiped, got %q", string(result.PrivateKey))
+	}
+	if len(secrets) != 1 || secrets[0] != "" {
+		t.Errorf("Expected the hidden secrets to be dropped, got %q", secrets)
+	}
+}
@@ -0,0 +1,401 @@
+// eip712.go
+
+package main
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TypedDataHashes holds the intermediate and final hashes of an EIP-712
+// payload.
+type TypedDataHashes struct {
+	DomainSeparator common.Hash
+	StructHash      common.Hash
+	Digest          common.Hash
+}
+
+// ParseTypedData decodes an EIP-712 JSON payload (domain, types, primaryType,
+// message). The EIP712Domain type is derived from the domain fields when the
+// payload omits it, as ethers.js does.
+func ParseTypedData(typedDataJSON string) (*apitypes.TypedData, error) {
+	var typedData apitypes.TypedData
+	if err := json.Unmarshal([]byte(typedDataJSON), &typedData); err != nil {
+		return nil, fmt.Errorf("invalid typed data JSON: %v", err)
+	}
+	if typedData.PrimaryType == "" {
+		return nil, fmt.Errorf("typed data is missing primaryType")
+	}
+	if _, ok := typedData.Types[typedData.PrimaryType]; !ok {
+		return nil, fmt.Errorf("primary type %q is not defined in types", typedData.PrimaryType)
+	}
+	if _, ok := typedData.Types["EIP712Domain"]; !ok {
+		typedData.Types["EIP712Domain"] = domainType(typedData.Domain)
+	}
+	return &typedData, nil
+}
+
+// domainType returns the EIP712Domain type for the fields set in domain, in
+// the order defined by the specification.
+func domainType(domain apitypes.TypedDataDomain) []apitypes.Type {
+	var fields []apitypes.Type
+	if domain.Name != "" {
+		fields = append(fields, apitypes.Type{Name: "name", Type: "string"})
+	}
+	if domain.Version != "" {
+		fields = append(fields, apitypes.Type{Name: "version", Type: "string"})
+	}
+	if domain.ChainId != nil {
+		fields = append(fields, apitypes.Type{Name: "chainId", Type: "uint256"})
+	}
+	if domain.VerifyingContract != "" {
+		fields = append(fields, apitypes.Type{Name: "verifyingContract", Type: "address"})
+	}
+	if domain.Salt != "" {
+		fields = append(fields, apitypes.Type{Name: "salt", Type: "bytes32"})
+	}
+	return fields
+}
+
+// HashTypedData computes the domain separator, struct hash and final
+// signing digest of an EIP-712 JSON payload.
+func HashTypedData(typedDataJSON string) (*TypedDataHashes, error) {
+	typedData, err := ParseTypedData(typedDataJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	domainSeparator, err := typedData.HashStruct("EIP712Domain", typedData.Domain.Map())
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash domain: %v", err)
+	}
+	structHash, err := typedData.HashStruct(typedData.PrimaryType, typedData.Message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash message: %v", err)
+	}
+
+	// keccak256("\x19\x01" ‖ domainSeparator ‖ hashStruct(message))
+	digest := crypto.Keccak256Hash([]byte{0x19, 0x01}, domainSeparator, structHash)
+
+	return &TypedDataHashes{
+		DomainSeparator: common.BytesToHash(domainSeparator),
+		StructHash:      common.BytesToHash(structHash),
+		Digest:          digest,
+	}, nil
+}
+
+// SignTypedData signs an EIP-712 JSON payload and returns the hex-encoded
+// signature with v as 27/28, as produced by eth_signTypedData_v4.
+func SignTypedData(privateKeyHex, typedDataJSON string) (string, error) {
+	privateKey, err := crypto.HexToECDSA(privateKeyHex)
+	if err != nil {
+		return "", fmt.Errorf("invalid private key: %v", err)
+	}
+	return signTypedDataWithKey(privateKey, typedDataJSON)
+}
+
+// signTypedDataWithKey signs an EIP-712 JSON payload with an already parsed
+// private key.
+func signTypedDataWithKey(privateKey *ecdsa.PrivateKey, typedDataJSON string) (string, error) {
+	hashes, err := HashTypedData(typedDataJSON)
+	if err != nil {
+		return "", err
+	}
+
+	signatureBytes, err := crypto.Sign(hashes.Digest.Bytes(), privateKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign typed data: %v", err)
+	}
+	signatureBytes[crypto.RecoveryIDOffset] += 27
+
+	return hexutil.Encode(signatureBytes), nil
+}
+
+// VerifyTypedData reports whether signatureHex is a valid signature of the
+// EIP-712 JSON payload by addressHex.
+func VerifyTypedData(typedDataJSON, signatureHex, addressHex string) (bool, error) {
+	if !common.IsHexAddress(addressHex) {
+		return false, fmt.Errorf("invalid Ethereum address")
+	}
+	address := common.HexToAddress(addressHex)
+
+	signatureBytes, err := decodeSignature(signatureHex)
+	if err != nil {
+		return false, err
+	}
+
+	hashes, err := HashTypedData(typedDataJSON)
+	if err != nil {
+		return false, err
+	}
+
+	recoveredAddr, err := recoverAddress(hashes.Digest.Bytes(), signatureBytes)
+	if err != nil {
+		return false, err
+	}
+	return recoveredAddr == address, nil
+}
+
+// readInputOrFile returns the contents of the named file when input starts
+// with "@", and input itself otherwise.
+func readInputOrFile(input string) (string, error) {
+	input = strings.TrimSpace(input)
+	if !strings.HasPrefix(input, "@") {
+		return input, nil
+	}
+	data, err := os.ReadFile(strings.TrimPrefix(input, "@"))
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %v", err)
+	}
+	return string(data), nil
+}
+
+// TypedDataResult is the outcome of signing or verifying typed data. Valid
+// is only set when a signature was verified.
+type TypedDataResult struct {
+	DomainSeparator string `json:"domainSeparator" yaml:"domainSeparator"`
+	StructHash      string `json:"structHash" yaml:"structHash"`
+	Digest          string `json:"digest" yaml:"digest"`
+	Signature       string `json:"signature" yaml:"signature"`
+	Address         string `json:"address,omitempty" yaml:"address,omitempty"`
+	Valid           *bool  `json:"valid,omitempty" yaml:"valid,omitempty"`
+}
+
+// NewTypedDataResult converts the hashes of typed data and its signature.
+func NewTypedDataResult(hashes *TypedDataHashes, signature string) *TypedDataResult {
+	return &TypedDataResult{
+		DomainSeparator: hashes.DomainSeparator.Hex(),
+		StructHash:      hashes.StructHash.Hex(),
+		Digest:          hashes.Digest.Hex(),
+		Signature:       signature,
+	}
+}
+
+// Text implements Result.
+func (r *TypedDataResult) Text() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Domain Separator: %s\n", r.DomainSeparator))
+	sb.WriteString(fmt.Sprintf("Struct Hash     : %s\n", r.StructHash))
+	sb.WriteString(fmt.Sprintf("Digest          : %s\n", r.Digest))
+	switch {
+	case r.Valid == nil:
+		sb.WriteString(fmt.Sprintf("\nSignature:\n%s", r.Signature))
+	case *r.Valid:
+		sb.WriteString("\nSignature is valid.")
+	default:
+		sb.WriteString("\nSignature is invalid.")
+	}
+	return sb.String()
+}
+
+// CopyFields implements Copyable.
+func (r *TypedDataResult) CopyFields() []CopyField {
+	return []CopyField{
+		{Label: "Digest", Value: r.Digest},
+		{Label: "Signature", Value: r.Signature},
+	}
+}
+
+func init() {
+	registerTool(110, "Sign/Verify Typed Data (EIP-712)", newTypedDataScreen)
+}
+
+// typedDataScreen signs or verifies an EIP-712 payload. Signing asks for the
+// private key and payload; verifying for the payload, signature and signer.
+type typedDataScreen struct {
+	screenID
+	session   *session
+	verify    bool
+	step      int
+	key       textInput
+	payload   textInput
+	signature textInput
+	address   textInput
+	content   string
+}
+
+func newTypedDataScreen(s *session) Screen {
+	return typedDataScreen{
+		screenID:  newScreenID(),
+		session:   s,
+		key:       newSecretInput(),
+		payload:   newMultilineInput(),
+		signature: newTextInput(),
+		address:   newTextInput(),
+	}
+}
+
+func (t typedDataScreen) Init() tea.Cmd {
+	return nil
+}
+
+func (t typedDataScreen) Update(msg tea.Msg) (Screen, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.Type {
+		case tea.KeyCtrlC, tea.KeyEsc:
+			return nil, nil
+		case tea.KeyTab:
+			// Switching modes reorders the fields, so only allow it up front
+			if t.step == 0 {
+				t.verify = !t.verify
+				t.content = ""
+			}
+		case tea.KeyEnter:
+			if t.verify {
+				return t.enterVerify()
+			}
+			return t.enterSign()
+		default:
+			input := t.input()
+			*input = input.Update(msg)
+		}
+	}
+	return t, nil
+}
+
+// input returns the input of the current step.
+func (t *typedDataScreen) input() *textInput {
+	switch {
+	case !t.verify && t.step == 0:
+		return &t.key
+	case t.step == 2:
+		return &t.address
+	case t.verify && t.step == 1:
+		return &t.signature
+	default:
+		return &t.payload
+	}
+}
+
+func (t typedDataScreen) enterSign() (Screen, tea.Cmd) {
+	key := t.key.Secret()
+	defer key.Wipe()
+	if t.step == 0 {
+		privateKey, err := t.session.signingKey(key)
+		if err != nil {
+			t.content = signingKeyError(err)
+			return t, nil
+		}
+		wipeKey(privateKey)
+		t.content = ""
+		t.step = 1
+		return t, nil
+	}
+
+	if t.payload.Value() == "" {
+		t.content = "Error: Typed data cannot be empty."
+		return t, nil
+	}
+	typedDataJSON, err := readInputOrFile(t.payload.Value())
+	if err != nil {
+		t.content = fmt.Sprintf("Error: %v", err)
+		return t, nil
+	}
+	hashes, err := HashTypedData(typedDataJSON)
+	if err != nil {
+		t.content = fmt.Sprintf("Error hashing typed data: %v", err)
+		return t, nil
+	}
+	privateKey, err := t.session.signingKey(key)
+	if err != nil {
+		t.content = signingKeyError(err)
+		return t, nil
+	}
+	defer wipeKey(privateKey)
+	signature, err := signTypedDataWithKey(privateKey, typedDataJSON)
+	if err != nil {
+		t.content = fmt.Sprintf("Error signing typed data: %v", err)
+		return t, nil
+	}
+	return t.session.showResult(NewTypedDataResult(hashes, signature)), nil
+}
+
+func (t typedDataScreen) enterVerify() (Screen, tea.Cmd) {
+	if t.step == 0 {
+		if t.payload.Value() == "" {
+			t.content = "Error: Typed data cannot be empty."
+			return t, nil
+		}
+		t.content = ""
+		t.step = 1
+		return t, nil
+	} else if t.step == 1 {
+		if t.signature.Value() == "" {
+			t.content = "Error: Signature cannot be empty."
+			return t, nil
+		}
+		t.content = ""
+		t.step = 2
+		return t, nil
+	}
+
+	if t.address.Value() == "" {
+		t.content = "Error: Ethereum address cannot be empty."
+		return t, nil
+	}
+	typedDataJSON, err := readInputOrFile(t.payload.Value())
+	if err != nil {
+		t.content = fmt.Sprintf("Error: %v", err)
+		return t, nil
+	}
+	hashes, err := HashTypedData(typedDataJSON)
+	if err != nil {
+		t.content = fmt.Sprintf("Error hashing typed data: %v", err)
+		return t, nil
+	}
+	signature, address := strings.TrimSpace(t.signature.Value()), strings.TrimSpace(t.address.Value())
+	valid, err := VerifyTypedData(typedDataJSON, signature, address)
+	if err != nil {
+		t.content = fmt.Sprintf("Error verifying signature: %v", err)
+		return t, nil
+	}
+	result := NewTypedDataResult(hashes, signature)
+	result.Address = address
+	result.Valid = &valid
+	return t.session.showResult(result), nil
+}
+
+func (t typedDataScreen) wipe() {
+	t.key.Reset()
+}
+
+func (t typedDataScreen) View() string {
+	s := titleStyle.Render("Sign/Verify Typed Data (EIP-712)") + "\n\n"
+	mode := "Sign"
+	if t.verify {
+		mode = "Verify"
+	}
+	s += fmt.Sprintf("Mode: %s", menuStyle.Render(mode))
+	if t.step == 0 {
+		s += " (press Tab to switch)"
+	}
+	s += "\n\n"
+
+	switch t.input() {
+	case &t.key:
+		s += "Enter your Ethereum private key (in hex format) or press Esc to cancel:\n"
+		s += t.session.unlockedHint()
+	case &t.payload:
+		s += "Enter the EIP-712 JSON payload (or @path/to/file.json) or press Esc to cancel:\n"
+	case &t.signature:
+		s += "Enter the signature (in hex format) or press Esc to cancel:\n"
+	default:
+		s += "Enter the Ethereum address of the signer or press Esc to cancel:\n"
+	}
+	s += t.input().View()
+	if t.content != "" {
+		s += "\n\n" + t.content + "\n\nPress Enter to continue..."
+	}
+	return s
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"testing"
+)
+
+// mailTypedData is the example payload from the EIP-712 specification.
+const mailTypedData = `{
+  "types": {
+    "EIP712Domain": [
+      {"name": "name", "type": "string"},
+      {"name": "version", "type": "string"},
+      {"name": "chainId", "type": "uint256"},
+      {"name": "verifyingContract", "type": "address"}
+    ],
+    "Person": [
+      {"name": "name", "type": "string"},
+      {"name": "wallet", "type": "address"}
+    ],
+    "Mail": [
+      {"name": "from", "type": "Person"},
+      {"name": "to", "type": "Person"},
+      {"name": "contents", "type": "string"}
+    ]
+  },
+  "primaryType": "Mail",
+  "domain": {
+    "name": "Ether Mail",
+    "version": "1",
+    "chainId": 1,
+    "verifyingContract": "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"
+  },
+  "message": {
+    "from": {"name": "Cow", "wallet": "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"},
+    "to": {"name": "Bob", "wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"},
+    "contents": "Hello, Bob!"
+  }
+}`
+
+func TestHashTypedData(t *testing.T) {
+	hashes, err := HashTypedData(mailTypedData)
+	if err != nil {
+		t.Fatalf("Failed to hash typed data: %v", err)
+	}
+
+	if got, want := hashes.DomainSeparator.Hex(), "0xf2cee375fa42b42143804025fc449deafd50cc031ca257e0b194a650a912090f"; got != want {
+		t.Errorf("Domain separator mismatch. Expected %s, got %s", want, got)
+	}
+	if got, want := hashes.StructHash.Hex(), "0xc52c0ee5d84264471806290a3f2c4cecfc5490626bf912d01f240d7a274b371e"; got != want {
+		t.Errorf("Struct hash mismatch. Expected %s, got %s", want, got)
+	}
+	if got, want := hashes.Digest.Hex(), "0xbe609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2"; got != want {
+		t.Errorf("Digest mismatch. Expected %s, got %s", want, got)
+	}
+}
+
+func TestSignAndVerifyTypedData(t *testing.T) {
+	// keccak256("cow"), the signer used in the EIP-712 specification
+	privateKeyHex := "c85ef7d79691fe79573b1a7064c19c1a9819ebdbd1faaab1a8ec92344438aaf4"
+	address := "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"
+	expected := "0x4355c47d63924e8a72e509b65029052eb6c299d53a04e167c5775fd466751c9d07299936d304c153f6443dfa05f40ff007d72911b6f72307f996231605b915621c"
+
+	signature, err := SignTypedData(privateKeyHex, mailTypedData)
+	if err != nil {
+		t.Fatalf("Failed to sign typed data: %v", err)
+	}
+	if signature != expected {
+		t.Fatalf("Signature mismatch. Expected %s, got %s", expected, signature)
+	}
+
+	valid, err := VerifyTypedData(mailTypedData, signature, address)
+	if err != nil {
+		t.Fatalf("Failed to verify typed data: %v", err)
+	}
+	if !valid {
+		t.Fatal("Typed data signature verification failed")
+	}
+
+	valid, err = VerifyTypedData(mailTypedData, signature, "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB")
+	if err != nil {
+		t.Fatalf("Failed to verify typed data: %v", err)
+	}
+	if valid {
+		t.Fatal("Typed data signature verified for the wrong address")
+	}
+}
+
+func TestParseTypedDataDerivesDomainType(t *testing.T) {
+	// Same payload without an explicit EIP712Domain type, as ethers.js sends it
+	payload := `{
+	  "types": {
+	    "Person": [{"name": "name", "type": "string"}, {"name": "wallet", "type": "address"}],
+	    "Mail": [{"name": "from", "type": "Person"}, {"name": "to", "type": "Person"}, {"name": "contents", "type": "string"}]
+	  },
+	  "primaryType": "Mail",
+	  "domain": {"name": "Ether Mail", "version": "1", "chainId": 1, "verifyingContract": "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"},
+	  "message": {
+	    "from": {"name": "Cow", "wallet": "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"},
+	    "to": {"name": "Bob", "wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"},
+	    "contents": "Hello, Bob!"
+	  }
+	}`
+
+	hashes, err := HashTypedData(payload)
+	if err != nil {
+		t.Fatalf("Failed to hash typed data: %v", err)
+	}
+	if got, want := hashes.Digest.Hex(), "0xbe609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2"; got != want {
+		t.Errorf("Digest mismatch. Expected %s, got %s", want, got)
+	}
+}
@@ -0,0 +1,231 @@
+// erc1271.go
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// erc1271MagicValue is returned by isValidSignature for a valid signature.
+var erc1271MagicValue = [4]byte{0x16, 0x26, 0xba, 0x7e}
+
+// erc6492MagicSuffix terminates ERC-6492 wrapped signatures.
+var erc6492MagicSuffix = common.FromHex("0x6492649264926492649264926492649264926492649264926492649264926492")
+
+const erc1271ABI = `[{"type":"function","name":"isValidSignature","stateMutability":"view",
+	"inputs":[{"name":"hash","type":"bytes32"},{"name":"signature","type":"bytes"}],
+	"outputs":[{"name":"magicValue","type":"bytes4"}]}]`
+
+var (
+	erc1271Contract, _ = abi.JSON(strings.NewReader(erc1271ABI))
+
+	addressType, _ = abi.NewType("address", "", nil)
+	bytesType, _   = abi.NewType("bytes", "", nil)
+
+	// erc6492Wrapper is the (factory, factoryCalldata, signature) tuple that
+	// precedes the ERC-6492 magic suffix.
+	erc6492Wrapper = abi.Arguments{{Type: addressType}, {Type: bytesType}, {Type: bytesType}}
+)
+
+// SignerType describes which kind of account a signature was verified for.
+type SignerType int
+
+const (
+	// SignerEOA is an externally owned account verified with ecrecover.
+	SignerEOA SignerType = iota
+	// SignerContract is a deployed smart account verified with ERC-1271.
+	SignerContract
+	// SignerCounterfactual is an undeployed smart account verified with
+	// ERC-6492.
+	SignerCounterfactual
+)
+
+// String returns a human readable name for the signer type.
+func (t SignerType) String() string {
+	switch t {
+	case SignerEOA:
+		return "EOA"
+	case SignerContract:
+		return "ERC-1271 contract"
+	case SignerCounterfactual:
+		return "ERC-6492 counterfactual contract"
+	default:
+		return fmt.Sprintf("SignerType(%d)", int(t))
+	}
+}
+
+// VerifySignatureOnChain verifies a message signature like VerifySignature,
+// but also accepts signatures from smart contract accounts by querying the
+// JSON-RPC endpoint at rpcURL.
+func VerifySignatureOnChain(ctx context.Context, rpcURL, message, signatureHex, addressHex string, scheme SignatureScheme) (bool, SignerType, error) {
+	if !common.IsHexAddress(addressHex) {
+		return false, SignerEOA, fmt.Errorf("invalid Ethereum address")
+	}
+	address := common.HexToAddress(addressHex)
+
+	// Raw bytes, since contract signatures need not be 65 bytes
+	signatureBytes, err := decodeSignatureHex(signatureHex)
+	if err != nil {
+		return false, SignerEOA, err
+	}
+
+	msgHash, err := hashMessage(message, scheme)
+	if err != nil {
+		return false, SignerEOA, err
+	}
+
+	client, err := rpc.DialContext(ctx, rpcURL)
+	if err != nil {
+		return false, SignerEOA, fmt.Errorf("failed to connect to RPC endpoint: %v", err)
+	}
+	defer client.Close()
+
+	return VerifyHashSignature(ctx, client, common.BytesToHash(msgHash), signatureBytes, address)
+}
+
+// VerifyHashSignature reports whether signature is valid for hash and
+// address. Accounts with code are checked with ERC-1271, ERC-6492 wrapped
+// signatures for undeployed accounts are checked by simulating the factory
+// deployment with eth_simulateV1, and anything else falls back to ecrecover.
+func VerifyHashSignature(ctx context.Context, client *rpc.Client, hash common.Hash, signature []byte, address common.Address) (bool, SignerType, error) {
+	eth := ethclient.NewClient(client)
+
+	code, err := eth.CodeAt(ctx, address, nil)
+	if err != nil {
+		return false, SignerEOA, fmt.Errorf("failed to fetch account code: %v", err)
+	}
+
+	if bytes.HasSuffix(signature, erc6492MagicSuffix) {
+		factory, factoryCalldata, innerSignature, err := unwrapERC6492(signature)
+		if err != nil {
+			return false, SignerCounterfactual, err
+		}
+		if len(code) > 0 {
+			valid, err := isValidSignature(ctx, eth, address, hash, innerSignature)
+			return valid, SignerContract, err
+		}
+		valid, err := isValidCounterfactualSignature(ctx, client, factory, factoryCalldata, address, hash, innerSignature)
+		return valid, SignerCounterfactual, err
+	}
+
+	if len(code) > 0 {
+		valid, err := isValidSignature(ctx, eth, address, hash, signature)
+		return valid, SignerContract, err
+	}
+
+	sig, err := ParseSignature(signature)
+	if err != nil {
+		return false, SignerEOA, err
+	}
+	recoveredAddr, err := recoverAddress(hash.Bytes(), sig.RecoveryBytes())
+	if err != nil {
+		return false, SignerEOA, err
+	}
+	return recoveredAddr == address, SignerEOA, nil
+}
+
+// unwrapERC6492 splits an ERC-6492 signature into the factory address, the
+// factory calldata and the inner signature.
+func unwrapERC6492(signature []byte) (common.Address, []byte, []byte, error) {
+	values, err := erc6492Wrapper.Unpack(signature[:len(signature)-len(erc6492MagicSuffix)])
+	if err != nil {
+		return common.Address{}, nil, nil, fmt.Errorf("invalid ERC-6492 signature: %v", err)
+	}
+	return values[0].(common.Address), values[1].([]byte), values[2].([]byte), nil
+}
+
+// WrapERC6492 wraps signature for a counterfactual account that factory
+// deploys when called with factoryCalldata.
+func WrapERC6492(factory common.Address, factoryCalldata, signature []byte) ([]byte, error) {
+	wrapped, err := erc6492Wrapper.Pack(factory, factoryCalldata, signature)
+	if err != nil {
+		return nil, err
+	}
+	return append(wrapped, erc6492MagicSuffix...), nil
+}
+
+func isValidSignature(ctx context.Context, caller ethereum.ContractCaller, address common.Address, hash common.Hash, signature []byte) (bool, error) {
+	data, err := erc1271Contract.Pack("isValidSignature", hash, signature)
+	if err != nil {
+		return false, err
+	}
+	result, err := caller.CallContract(ctx, ethereum.CallMsg{To: &address, Data: data}, nil)
+	if isRevert(err) {
+		// Reverting is how many wallets reject a signature
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to call isValidSignature: %w", err)
+	}
+	return isMagicValue(result), nil
+}
+
+// isRevert reports whether err is a call that reverted, as opposed to the
+// node failing to run it because of a network error, a timeout or a rate
+// limit.
+func isRevert(err error) bool {
+	var rpcErr rpc.Error
+	if !errors.As(err, &rpcErr) {
+		return false
+	}
+	// Geth and anvil use code 3 for reverts with data, other nodes only
+	// say so in the message
+	return rpcErr.ErrorCode() == 3 || strings.Contains(strings.ToLower(rpcErr.Error()), "revert")
+}
+
+func isValidCounterfactualSignature(ctx context.Context, client *rpc.Client, factory common.Address, factoryCalldata []byte, address common.Address, hash common.Hash, signature []byte) (bool, error) {
+	data, err := erc1271Contract.Pack("isValidSignature", hash, signature)
+	if err != nil {
+		return false, err
+	}
+
+	type simCall struct {
+		To    common.Address `json:"to"`
+		Input hexutil.Bytes  `json:"input"`
+	}
+	type simBlock struct {
+		Calls []simCall `json:"calls"`
+	}
+	opts := map[string]interface{}{
+		"blockStateCalls": []simBlock{{Calls: []simCall{
+			{To: factory, Input: factoryCalldata},
+			{To: address, Input: data},
+		}}},
+	}
+
+	var blocks []struct {
+		Calls []struct {
+			ReturnData hexutil.Bytes  `json:"returnData"`
+			Status     hexutil.Uint64 `json:"status"`
+		} `json:"calls"`
+	}
+	if err := client.CallContext(ctx, &blocks, "eth_simulateV1", opts, "latest"); err != nil {
+		return false, fmt.Errorf("failed to simulate account deployment: %v", err)
+	}
+	if len(blocks) != 1 || len(blocks[0].Calls) != 2 {
+		return false, fmt.Errorf("unexpected eth_simulateV1 response")
+	}
+
+	deploy, check := blocks[0].Calls[0], blocks[0].Calls[1]
+	if deploy.Status != 1 {
+		return false, fmt.Errorf("factory call reverted")
+	}
+	return check.Status == 1 && isMagicValue(check.ReturnData), nil
+}
+
+// isMagicValue reports whether an ABI-encoded bytes4 result is the ERC-1271
+// magic value.
+func isMagicValue(result []byte) bool {
+	return len(result) >= 4 && bytes.Equal(result[:4], erc1271MagicValue[:])
+}
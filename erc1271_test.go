@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// stubSmartAccountAPI is a minimal stand-in for an anvil node hosting a
+// smart account that accepts signatures made by its owner key, and a
+// factory that deploys it.
+type stubSmartAccountAPI struct {
+	owner           common.Address
+	account         common.Address
+	factory         common.Address
+	factoryCalldata []byte
+	deployed        bool
+	// callErr, if set, is returned by eth_call instead of running it
+	callErr error
+}
+
+// stubRPCError is a JSON-RPC error with a code, such as a revert.
+type stubRPCError struct {
+	code    int
+	message string
+}
+
+func (e *stubRPCError) Error() string  { return e.message }
+func (e *stubRPCError) ErrorCode() int { return e.code }
+
+type stubCallArgs struct {
+	To    *common.Address `json:"to"`
+	Input hexutil.Bytes   `json:"input"`
+}
+
+func (api *stubSmartAccountAPI) GetCode(address common.Address, block string) hexutil.Bytes {
+	if address == api.account && api.deployed {
+		return hexutil.Bytes{0x60, 0x00}
+	}
+	return nil
+}
+
+func (api *stubSmartAccountAPI) Call(args stubCallArgs, block string) (hexutil.Bytes, error) {
+	if api.callErr != nil {
+		return nil, api.callErr
+	}
+	return api.call(args, api.deployed), nil
+}
+
+func (api *stubSmartAccountAPI) SimulateV1(opts struct {
+	BlockStateCalls []struct {
+		Calls []stubCallArgs `json:"calls"`
+	} `json:"blockStateCalls"`
+}, block string) ([]map[string]interface{}, error) {
+	deployed := api.deployed
+	var results []map[string]interface{}
+	for _, block := range opts.BlockStateCalls {
+		var calls []map[string]interface{}
+		for _, call := range block.Calls {
+			if *call.To == api.factory && bytes.Equal(call.Input, api.factoryCalldata) {
+				deployed = true
+			}
+			calls = append(calls, map[string]interface{}{
+				"returnData": api.call(call, deployed),
+				"status":     hexutil.Uint64(1),
+			})
+		}
+		results = append(results, map[string]interface{}{"calls": calls})
+	}
+	return results, nil
+}
+
+// call executes isValidSignature on the stub account.
+func (api *stubSmartAccountAPI) call(args stubCallArgs, deployed bool) hexutil.Bytes {
+	if *args.To != api.account || !deployed {
+		return nil
+	}
+	values, err := erc1271Contract.Methods["isValidSignature"].Inputs.Unpack(args.Input[4:])
+	if err != nil {
+		return nil
+	}
+	hash := values[0].([32]byte)
+	signature := common.CopyBytes(values[1].([]byte))
+	signature[crypto.RecoveryIDOffset] -= 27
+
+	result := make([]byte, 32)
+	if signer, err := recoverAddress(hash[:], signature); err == nil && signer == api.owner {
+		copy(result, erc1271MagicValue[:])
+	} else {
+		copy(result, []byte{0xff, 0xff, 0xff, 0xff})
+	}
+	return result
+}
+
+func newStubNode(t *testing.T, api *stubSmartAccountAPI) string {
+	server := rpc.NewServer()
+	if err := server.RegisterName("eth", api); err != nil {
+		t.Fatalf("Failed to register stub API: %v", err)
+	}
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(func() {
+		httpServer.Close()
+		server.Stop()
+	})
+	return httpServer.URL
+}
+
+func TestVerifySignatureOnChain(t *testing.T) {
+	ownerKeyHex := "4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+	otherKeyHex := "c85ef7d79691fe79573b1a7064c19c1a9819ebdbd1faaab1a8ec92344438aaf4"
+	api := &stubSmartAccountAPI{
+		owner:           common.HexToAddress("0x2c7536E3605D9C16a7a3D7b1898e529396a65c23"),
+		account:         common.HexToAddress("0x1111111111111111111111111111111111111111"),
+		factory:         common.HexToAddress("0x2222222222222222222222222222222222222222"),
+		factoryCalldata: []byte{0xde, 0xad, 0xbe, 0xef},
+	}
+	rpcURL := newStubNode(t, api)
+	ctx := context.Background()
+	message := "Hello, smart account"
+
+	ownerSig, err := SignMessage(ownerKeyHex, message, SchemeEIP191)
+	if err != nil {
+		t.Fatalf("Failed to sign message: %v", err)
+	}
+	otherSig, err := SignMessage(otherKeyHex, message, SchemeEIP191)
+	if err != nil {
+		t.Fatalf("Failed to sign message: %v", err)
+	}
+
+	// Plain EOA signatures still verify through ecrecover
+	valid, signerType, err := VerifySignatureOnChain(ctx, rpcURL, message, ownerSig, api.owner.Hex(), SchemeEIP191)
+	if err != nil || !valid || signerType != SignerEOA {
+		t.Fatalf("EOA verification failed: valid=%v type=%v err=%v", valid, signerType, err)
+	}
+
+	// Pasted signatures are accepted as by the offline VerifySignature
+	pasted := "  " + strings.TrimPrefix(ownerSig, "0x") + "\n"
+	if valid, err := VerifySignature(message, pasted, api.owner.Hex(), SchemeEIP191); err != nil || !valid {
+		t.Fatalf("Offline verification of a pasted signature failed: valid=%v err=%v", valid, err)
+	}
+	valid, _, err = VerifySignatureOnChain(ctx, rpcURL, message, pasted, api.owner.Hex(), SchemeEIP191)
+	if err != nil || !valid {
+		t.Fatalf("On-chain verification of a pasted signature failed: valid=%v err=%v", valid, err)
+	}
+
+	// Undeployed account with an ERC-6492 wrapped signature
+	wrapped, err := WrapERC6492(api.factory, api.factoryCalldata, hexutil.MustDecode(ownerSig))
+	if err != nil {
+		t.Fatalf("Failed to wrap signature: %v", err)
+	}
+	valid, signerType, err = VerifySignatureOnChain(ctx, rpcURL, message, hexutil.Encode(wrapped), api.account.Hex(), SchemeEIP191)
+	if err != nil || !valid || signerType != SignerCounterfactual {
+		t.Fatalf("ERC-6492 verification failed: valid=%v type=%v err=%v", valid, signerType, err)
+	}
+
+	// Deployed account verified with ERC-1271
+	api.deployed = true
+	valid, signerType, err = VerifySignatureOnChain(ctx, rpcURL, message, ownerSig, api.account.Hex(), SchemeEIP191)
+	if err != nil || !valid || signerType != SignerContract {
+		t.Fatalf("ERC-1271 verification failed: valid=%v type=%v err=%v", valid, signerType, err)
+	}
+
+	valid, _, err = VerifySignatureOnChain(ctx, rpcURL, message, otherSig, api.account.Hex(), SchemeEIP191)
+	if err != nil {
+		t.Fatalf("Failed to verify signature: %v", err)
+	}
+	if valid {
+		t.Fatal("Signature from a non-owner key was accepted")
+	}
+
+	// Reverting rejects the signature, but a failing node is an error
+	api.callErr = &stubRPCError{code: 3, message: "execution reverted"}
+	valid, _, err = VerifySignatureOnChain(ctx, rpcURL, message, ownerSig, api.account.Hex(), SchemeEIP191)
+	if err != nil || valid {
+		t.Errorf("Expected a revert to make the signature invalid, got valid=%v err=%v", valid, err)
+	}
+	api.callErr = &stubRPCError{code: -32005, message: "rate limit exceeded"}
+	if _, _, err := VerifySignatureOnChain(ctx, rpcURL, message, ownerSig, api.account.Hex(), SchemeEIP191); err == nil {
+		t.Error("Expected an RPC failure to be reported as an error")
+	}
+}
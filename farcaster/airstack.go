@@ -0,0 +1,107 @@
+// airstack.go
+
+package farcaster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"example.com/ethgotools/airstack"
+)
+
+// Airstack is a Provider backed by the Airstack GraphQL API.
+type Airstack struct {
+	Client *airstack.AirstackClient
+}
+
+// NewAirstack returns a provider that queries Airstack with client.
+func NewAirstack(client *airstack.AirstackClient) *Airstack {
+	return &Airstack{Client: client}
+}
+
+// Name implements Provider.
+func (a *Airstack) Name() string {
+	return "Airstack"
+}
+
+// Profile implements Provider.
+func (a *Airstack) Profile(ctx context.Context, username string) (*Profile, error) {
+	social, err := a.Client.QueryFarcasterProfileContext(ctx, username)
+	if err != nil {
+		return nil, airstackError(err)
+	}
+	fid, err := strconv.ParseUint(social.UserID, 10, 64)
+	if err != nil {
+		return nil, withKind(ErrSchema, fmt.Errorf("invalid fid %q in Airstack profile", social.UserID))
+	}
+	profile := &Profile{
+		FID:            fid,
+		Username:       social.ProfileName,
+		DisplayName:    social.ProfileDisplayName,
+		Bio:            social.ProfileBio,
+		FollowerCount:  &social.FollowerCount,
+		FollowingCount: &social.FollowingCount,
+	}
+	if social.FarcasterScore != nil {
+		profile.Score = &social.FarcasterScore.FarScore
+	}
+	return profile, nil
+}
+
+// Casts implements Provider.
+func (a *Airstack) Casts(ctx context.Context, fid uint64, limit int) ([]Cast, error) {
+	result, err := a.Client.QueryFarcasterCastsContext(ctx, fid, limit)
+	if err != nil {
+		return nil, airstackError(err)
+	}
+	casts := make([]Cast, 0, len(result))
+	for _, c := range result {
+		cast := Cast{Hash: c.Hash, Text: c.Text}
+		// Timestamps are informative; a missing one is not an error
+		cast.Timestamp, _ = time.Parse(time.RFC3339, c.CastedAtTimestamp)
+		if c.Channel != nil {
+			cast.Channel = c.Channel.ChannelID
+		}
+		casts = append(casts, cast)
+	}
+	return casts, nil
+}
+
+// Channels implements Provider.
+func (a *Airstack) Channels(ctx context.Context, fid uint64, limit int) ([]Channel, error) {
+	result, err := a.Client.QueryFarcasterChannelsContext(ctx, fid, limit)
+	if err != nil {
+		return nil, airstackError(err)
+	}
+	channels := make([]Channel, 0, len(result))
+	for _, c := range result {
+		channels = append(channels, Channel{
+			ID:            c.ChannelID,
+			Name:          c.Name,
+			Description:   c.Description,
+			FollowerCount: c.FollowerCount,
+			URL:           c.URL,
+		})
+	}
+	return channels, nil
+}
+
+// airstackError makes an error of the Airstack client match the sentinel
+// errors of this package.
+func airstackError(err error) error {
+	for _, kind := range []struct{ from, to error }{
+		{airstack.ErrNotFound, ErrNotFound},
+		{airstack.ErrUnauthorized, ErrUnauthorized},
+		{airstack.ErrNoAPIKey, ErrUnauthorized},
+		{airstack.ErrRateLimited, ErrRateLimited},
+		{airstack.ErrSchema, ErrSchema},
+	} {
+		if errors.Is(err, kind.from) {
+			return withKind(kind.to, err)
+		}
+	}
+	return err
+}
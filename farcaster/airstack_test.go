@@ -0,0 +1,53 @@
+package farcaster
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"example.com/ethgotools/airstack"
+	"example.com/ethgotools/airstack/airstacktest"
+)
+
+func TestAirstack(t *testing.T) {
+	server := airstacktest.NewServer()
+	defer server.Close()
+	provider := NewAirstack(server.AirstackClient())
+
+	// The fixture has three casts and two channels, cut to the limit
+	account, err := Lookup(context.Background(), provider, "dwr", 2)
+	if err != nil {
+		t.Fatalf("Lookup failed: %v", err)
+	}
+	profile := account.Profile
+	if profile.FID != 3 || profile.Username != "dwr.eth" || profile.DisplayName != "Dan Romero" ||
+		*profile.FollowerCount != 318204 || *profile.Score != 98.41 {
+		t.Errorf("Unexpected profile %+v", profile)
+	}
+	if len(account.Casts) != 2 || account.Casts[0].Text != "gm" || account.Casts[1].Channel != "farcaster" ||
+		account.Casts[0].Timestamp.Format("2006-01-02") != "2024-09-02" {
+		t.Errorf("Unexpected casts %+v", account.Casts)
+	}
+	if len(account.Channels) != 2 || account.Channels[1].ID != "product" || account.Channels[1].FollowerCount != 15210 {
+		t.Errorf("Unexpected channels %+v", account.Channels)
+	}
+	for _, request := range server.Requests() {
+		if limit := request.Variables.(airstack.FarcasterAccountVariables).Limit; limit != 0 && limit != 2 {
+			t.Errorf("Expected the limit to be passed on, got %d", limit)
+		}
+	}
+
+	if account, err := Lookup(context.Background(), provider, "v", 5); err != nil || len(account.Casts) != 0 || account.Channels == nil {
+		t.Errorf("Expected an account without casts or channels, got %+v, %v", account, err)
+	}
+
+	// Errors match both the errors of this package and the Airstack ones
+	_, err = provider.Profile(context.Background(), "nobody")
+	if !errors.Is(err, ErrNotFound) || !errors.Is(err, airstack.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+	provider.Client.SetAPIKey("wrong-key")
+	if _, err := provider.Profile(context.Background(), "dwr"); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Expected ErrUnauthorized, got %v", err)
+	}
+}
@@ -0,0 +1,64 @@
+// errors.go
+
+package farcaster
+
+import (
+	"errors"
+
+	"example.com/ethgotools/internal/httpretry"
+)
+
+// Errors that failed lookups can be matched against with errors.Is, whatever
+// the provider. The original error of the provider stays in the chain.
+var (
+	// ErrNotFound means the account does not exist.
+	ErrNotFound = errors.New("farcaster: not found")
+	// ErrUnauthorized means the API key was missing, invalid or not allowed
+	// to make the request.
+	ErrUnauthorized = errors.New("farcaster: unauthorized")
+	// ErrRateLimited means too many requests were made with the API key.
+	ErrRateLimited = errors.New("farcaster: rate limited")
+	// ErrSchema means the request or response does not match the API of the
+	// provider, for example after a field was removed.
+	ErrSchema = errors.New("farcaster: request does not match the API")
+	// ErrUnsupported means the provider does not have the requested data.
+	ErrUnsupported = errors.New("farcaster: not supported by the provider")
+)
+
+// StatusError is a response of a REST provider with an HTTP status other
+// than 200 OK.
+type StatusError struct {
+	*httpretry.StatusError
+}
+
+// statusSentinels are the errors a StatusError is matched against.
+var statusSentinels = httpretry.Sentinels{Unauthorized: ErrUnauthorized, RateLimited: ErrRateLimited, NotFound: ErrNotFound}
+
+// Is matches the status code against the sentinel errors.
+func (e *StatusError) Is(target error) bool {
+	return e.Matches(target, statusSentinels)
+}
+
+func (e *StatusError) Unwrap() error {
+	return e.StatusError
+}
+
+// kindError is an error of a provider that corresponds to one of the
+// sentinel errors, which the provider's own error does not know about.
+type kindError struct {
+	kind error
+	err  error
+}
+
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+func (e *kindError) Unwrap() []error {
+	return []error{e.kind, e.err}
+}
+
+// withKind returns err matching kind as well.
+func withKind(kind, err error) error {
+	return &kindError{kind: kind, err: err}
+}
@@ -0,0 +1,94 @@
+// farcaster.go
+
+// Package farcaster looks up Farcaster accounts through interchangeable
+// data providers: the Airstack GraphQL API, the Neynar REST API and the
+// HTTP API of a Farcaster Hub. Each implements Provider and returns the
+// same domain types, so callers do not depend on any one vendor.
+package farcaster
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Profile is the profile of a Farcaster account.
+type Profile struct {
+	FID         uint64
+	Username    string
+	DisplayName string
+	Bio         string
+	// FollowerCount and FollowingCount are nil when the provider cannot
+	// count them, as with hubs.
+	FollowerCount  *int
+	FollowingCount *int
+	// Score is the reputation score the provider gives the account, such
+	// as Airstack's FarScore or Neynar's user score, or nil if it has none.
+	Score *float64
+}
+
+// Cast is a message published by a Farcaster account.
+type Cast struct {
+	Hash      string
+	Text      string
+	Timestamp time.Time
+	// Channel is the ID of the channel the cast was posted in, such as
+	// "memes", or the parent URL of the cast when it is not a known
+	// channel. It is empty for casts outside channels.
+	Channel string
+}
+
+// Channel is a Farcaster channel.
+type Channel struct {
+	ID            string
+	Name          string
+	Description   string
+	FollowerCount int
+	URL           string
+}
+
+// Provider is a source of Farcaster data. Methods return ErrNotFound for
+// accounts that do not exist and ErrUnsupported for data the provider does
+// not have.
+type Provider interface {
+	// Name returns the name of the provider to show to users.
+	Name() string
+	// Profile returns the profile of the account with the given username.
+	Profile(ctx context.Context, username string) (*Profile, error)
+	// Casts returns up to limit of the latest casts of the account with the
+	// given fid, newest first.
+	Casts(ctx context.Context, fid uint64, limit int) ([]Cast, error)
+	// Channels returns up to limit of the channels the account with the
+	// given fid takes part in.
+	Channels(ctx context.Context, fid uint64, limit int) ([]Channel, error)
+}
+
+// Account is the profile of an account with its latest casts and channels.
+// Channels is nil when the provider does not support them.
+type Account struct {
+	Profile  Profile
+	Casts    []Cast
+	Channels []Channel
+}
+
+// Lookup fetches the profile of the account with the given username from p,
+// with up to limit casts and channels.
+func Lookup(ctx context.Context, p Provider, username string, limit int) (*Account, error) {
+	profile, err := p.Profile(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	account := &Account{Profile: *profile}
+	if account.Casts, err = p.Casts(ctx, profile.FID, limit); err != nil {
+		return nil, err
+	}
+	account.Channels, err = p.Channels(ctx, profile.FID, limit)
+	if errors.Is(err, ErrUnsupported) {
+		account.Channels = nil
+	} else if err != nil {
+		return nil, err
+	} else if account.Channels == nil {
+		account.Channels = []Channel{}
+	}
+	return account, nil
+}
@@ -0,0 +1,68 @@
+// http.go
+
+package farcaster
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"example.com/ethgotools/internal/httpretry"
+)
+
+// restClient sends GET requests to a JSON REST API, with the same timeout
+// and retry policy as the Airstack client.
+type restClient struct {
+	// BaseURL is the URL the paths of requests are appended to.
+	BaseURL    string
+	HTTPClient *http.Client
+	httpretry.Policy
+
+	header http.Header
+}
+
+func newRESTClient(baseURL string) restClient {
+	return restClient{
+		BaseURL:    baseURL,
+		HTTPClient: &http.Client{},
+		Policy:     httpretry.DefaultPolicy(),
+		header:     http.Header{},
+	}
+}
+
+// get requests path with the query parameters and decodes the JSON response
+// into result. Transient failures are retried until ctx is done.
+func (c *restClient) get(ctx context.Context, path string, query url.Values, result any) error {
+	endpoint := strings.TrimSuffix(c.BaseURL, "/") + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	body, err := c.Policy.Do(ctx, c.HTTPClient, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", "application/json")
+		for name, values := range c.header {
+			req.Header[name] = values
+		}
+		return req, nil
+	})
+	var status *httpretry.StatusError
+	if errors.As(err, &status) {
+		return &StatusError{status}
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(body, result); err != nil {
+		return fmt.Errorf("error parsing JSON response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+// hub.go
+
+package farcaster
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// farcasterEpoch is the start of the timestamps of hub messages.
+var farcasterEpoch = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// channelURLPrefix starts the parent URLs of casts in channels created on
+// Warpcast.
+const channelURLPrefix = "https://warpcast.com/~/channel/"
+
+// Hub is a Provider backed by the HTTP API of a Farcaster Hub, such as a
+// local Hubble node. Hubs do not count followers, score accounts or know
+// about channels besides the parent URLs of casts.
+type Hub struct {
+	restClient
+}
+
+// NewHub returns a provider that queries the hub at baseURL, such as
+// http://localhost:2281.
+func NewHub(baseURL string) *Hub {
+	return &Hub{restClient: newRESTClient(baseURL)}
+}
+
+// SetAPIKey sets the API key sent to hosted hubs that require one.
+func (h *Hub) SetAPIKey(apiKey string) {
+	h.header.Set("x-api-key", apiKey)
+}
+
+// Name implements Provider.
+func (h *Hub) Name() string {
+	return "Farcaster Hub"
+}
+
+// hubMessage is a message of the hub API, with the bodies of the message
+// types read by Hub.
+type hubMessage struct {
+	Data struct {
+		Type         string `json:"type"`
+		Timestamp    int64  `json:"timestamp"`
+		UserDataBody *struct {
+			Type  string `json:"type"`
+			Value string `json:"value"`
+		} `json:"userDataBody"`
+		CastAddBody *struct {
+			Text      string `json:"text"`
+			ParentURL string `json:"parentUrl"`
+		} `json:"castAddBody"`
+	} `json:"data"`
+	Hash string `json:"hash"`
+}
+
+// Profile implements Provider.
+func (h *Hub) Profile(ctx context.Context, username string) (*Profile, error) {
+	var proof struct {
+		Name string `json:"name"`
+		FID  uint64 `json:"fid"`
+	}
+	if err := h.get(ctx, "/v1/userNameProofByName", url.Values{"name": {username}}, &proof); err != nil {
+		return nil, err
+	}
+	if proof.FID == 0 {
+		return nil, withKind(ErrSchema, fmt.Errorf("no fid for %q in hub username proof", username))
+	}
+
+	var result struct {
+		Messages []hubMessage `json:"messages"`
+	}
+	fid := strconv.FormatUint(proof.FID, 10)
+	if err := h.get(ctx, "/v1/userDataByFid", url.Values{"fid": {fid}}, &result); err != nil {
+		return nil, err
+	}
+	profile := &Profile{FID: proof.FID, Username: proof.Name}
+	for _, message := range result.Messages {
+		body := message.Data.UserDataBody
+		if body == nil {
+			continue
+		}
+		switch body.Type {
+		case "USER_DATA_TYPE_USERNAME":
+			profile.Username = body.Value
+		case "USER_DATA_TYPE_DISPLAY":
+			profile.DisplayName = body.Value
+		case "USER_DATA_TYPE_BIO":
+			profile.Bio = body.Value
+		}
+	}
+	return profile, nil
+}
+
+// Casts implements Provider.
+func (h *Hub) Casts(ctx context.Context, fid uint64, limit int) ([]Cast, error) {
+	var result struct {
+		Messages []hubMessage `json:"messages"`
+	}
+	query := url.Values{
+		"fid":      {strconv.FormatUint(fid, 10)},
+		"pageSize": {strconv.Itoa(limit)},
+		"reverse":  {"true"},
+	}
+	if err := h.get(ctx, "/v1/castsByFid", query, &result); err != nil {
+		return nil, err
+	}
+	casts := make([]Cast, 0, len(result.Messages))
+	for _, message := range result.Messages {
+		body := message.Data.CastAddBody
+		if message.Data.Type != "MESSAGE_TYPE_CAST_ADD" || body == nil {
+			continue
+		}
+		casts = append(casts, Cast{
+			Hash:      message.Hash,
+			Text:      body.Text,
+			Timestamp: farcasterEpoch.Add(time.Duration(message.Data.Timestamp) * time.Second),
+			Channel:   strings.TrimPrefix(body.ParentURL, channelURLPrefix),
+		})
+	}
+	return casts, nil
+}
+
+// Channels implements Provider. Hubs do not store channels.
+func (h *Hub) Channels(ctx context.Context, fid uint64, limit int) ([]Channel, error) {
+	return nil, ErrUnsupported
+}
+
+// get is like restClient.get, and also recognizes the not_found error code
+// hubs answer unknown names and fids with.
+func (h *Hub) get(ctx context.Context, path string, query url.Values, result any) error {
+	err := h.restClient.get(ctx, path, query, result)
+	var status *StatusError
+	if errors.As(err, &status) {
+		var hubErr struct {
+			ErrCode string `json:"errCode"`
+		}
+		if json.Unmarshal([]byte(status.Body), &hubErr) == nil && strings.HasPrefix(hubErr.ErrCode, "not_found") {
+			return withKind(ErrNotFound, err)
+		}
+	}
+	return err
+}
@@ -0,0 +1,50 @@
+package farcaster
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestHub(t *testing.T) {
+	server := stubServer(t, "", http.StatusBadRequest, `{"errCode":"not_found","presentable":false,"name":"HubError","code":3,"details":"NotFound"}`, map[string]string{
+		"/v1/userNameProofByName?name=dwr":   `{"timestamp":1628882891,"name":"dwr","owner":"0x74232bf61e994655592747e20bdf6fa9b9476f79","fid":3,"type":"USERNAME_TYPE_FNAME"}`,
+		"/v1/userNameProofByName?name=empty": `{}`,
+		"/v1/userDataByFid?fid=3": `{"messages":[
+			{"data":{"type":"MESSAGE_TYPE_USER_DATA_ADD","fid":3,"timestamp":100,"userDataBody":{"type":"USER_DATA_TYPE_PFP","value":"https://example.com/dwr.png"}},"hash":"0xa1"},
+			{"data":{"type":"MESSAGE_TYPE_USER_DATA_ADD","fid":3,"timestamp":101,"userDataBody":{"type":"USER_DATA_TYPE_DISPLAY","value":"Dan Romero"}},"hash":"0xa2"},
+			{"data":{"type":"MESSAGE_TYPE_USER_DATA_ADD","fid":3,"timestamp":102,"userDataBody":{"type":"USER_DATA_TYPE_USERNAME","value":"dwr.eth"}},"hash":"0xa3"}],
+			"nextPageToken":""}`,
+		"/v1/castsByFid?fid=3&pageSize=5&reverse=true": `{"messages":[
+			{"data":{"type":"MESSAGE_TYPE_CAST_ADD","fid":3,"timestamp":115000000,"castAddBody":{"text":"gm","parentUrl":"https://warpcast.com/~/channel/memes"}},"hash":"0x01"},
+			{"data":{"type":"MESSAGE_TYPE_CAST_ADD","fid":3,"timestamp":114999000,"castAddBody":{"text":"reply","parentCastId":{"fid":2,"hash":"0x99"}}},"hash":"0x02"}],
+			"nextPageToken":"AuzO1V0Dta"}`,
+	})
+	provider := NewHub(server.URL)
+
+	account, err := Lookup(context.Background(), provider, "dwr", 5)
+	if err != nil {
+		t.Fatalf("Lookup failed: %v", err)
+	}
+	if p := account.Profile; p.FID != 3 || p.Username != "dwr.eth" || p.DisplayName != "Dan Romero" || p.FollowerCount != nil || p.Score != nil {
+		t.Errorf("Unexpected profile %+v", p)
+	}
+	want := time.Date(2024, 8, 24, 0, 26, 40, 0, time.UTC)
+	if c := account.Casts; len(c) != 2 || !c[0].Timestamp.Equal(want) || c[0].Channel != "memes" || c[1].Channel != "" {
+		t.Errorf("Unexpected casts %+v", c)
+	}
+	if account.Channels != nil {
+		t.Errorf("Expected no channels from a hub, got %+v", account.Channels)
+	}
+
+	// Hubs answer unknown names with 400 and an error code
+	if _, err := provider.Profile(context.Background(), "nobody"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+	// A proof without a fid is not an account with FID 0
+	if profile, err := provider.Profile(context.Background(), "empty"); !errors.Is(err, ErrSchema) {
+		t.Errorf("Expected ErrSchema, got %+v, %v", profile, err)
+	}
+}
@@ -0,0 +1,118 @@
+// neynar.go
+
+package farcaster
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// NeynarEndpoint is the URL of the Neynar REST API, used unless the
+// provider has another BaseURL.
+const NeynarEndpoint = "https://api.neynar.com"
+
+// Neynar is a Provider backed by the Neynar REST API.
+type Neynar struct {
+	restClient
+}
+
+// NewNeynar returns a provider that queries the Neynar API with apiKey.
+func NewNeynar(apiKey string) *Neynar {
+	n := &Neynar{restClient: newRESTClient(NeynarEndpoint)}
+	n.header.Set("x-api-key", apiKey)
+	return n
+}
+
+// Name implements Provider.
+func (n *Neynar) Name() string {
+	return "Neynar"
+}
+
+// Profile implements Provider.
+func (n *Neynar) Profile(ctx context.Context, username string) (*Profile, error) {
+	var result struct {
+		User struct {
+			FID         uint64 `json:"fid"`
+			Username    string `json:"username"`
+			DisplayName string `json:"display_name"`
+			Profile     struct {
+				Bio struct {
+					Text string `json:"text"`
+				} `json:"bio"`
+			} `json:"profile"`
+			FollowerCount  int      `json:"follower_count"`
+			FollowingCount int      `json:"following_count"`
+			Score          *float64 `json:"score"`
+		} `json:"user"`
+	}
+	query := url.Values{"username": {username}}
+	if err := n.get(ctx, "/v2/farcaster/user/by_username", query, &result); err != nil {
+		return nil, err
+	}
+	user := result.User
+	if user.FID == 0 {
+		return nil, withKind(ErrSchema, fmt.Errorf("no user for %q in Neynar response", username))
+	}
+	return &Profile{
+		FID:            user.FID,
+		Username:       user.Username,
+		DisplayName:    user.DisplayName,
+		Bio:            user.Profile.Bio.Text,
+		FollowerCount:  &user.FollowerCount,
+		FollowingCount: &user.FollowingCount,
+		Score:          user.Score,
+	}, nil
+}
+
+// Casts implements Provider.
+func (n *Neynar) Casts(ctx context.Context, fid uint64, limit int) ([]Cast, error) {
+	var result struct {
+		Casts []struct {
+			Hash      string    `json:"hash"`
+			Text      string    `json:"text"`
+			Timestamp time.Time `json:"timestamp"`
+			ParentURL string    `json:"parent_url"`
+			Channel   *struct {
+				ID string `json:"id"`
+			} `json:"channel"`
+		} `json:"casts"`
+	}
+	query := url.Values{"fid": {strconv.FormatUint(fid, 10)}, "limit": {strconv.Itoa(limit)}}
+	if err := n.get(ctx, "/v2/farcaster/feed/user/casts", query, &result); err != nil {
+		return nil, err
+	}
+	casts := make([]Cast, 0, len(result.Casts))
+	for _, c := range result.Casts {
+		cast := Cast{Hash: c.Hash, Text: c.Text, Timestamp: c.Timestamp, Channel: c.ParentURL}
+		if c.Channel != nil {
+			cast.Channel = c.Channel.ID
+		}
+		casts = append(casts, cast)
+	}
+	return casts, nil
+}
+
+// Channels implements Provider.
+func (n *Neynar) Channels(ctx context.Context, fid uint64, limit int) ([]Channel, error) {
+	var result struct {
+		Channels []struct {
+			ID            string `json:"id"`
+			Name          string `json:"name"`
+			Description   string `json:"description"`
+			FollowerCount int    `json:"follower_count"`
+			URL           string `json:"url"`
+		} `json:"channels"`
+	}
+	query := url.Values{"fid": {strconv.FormatUint(fid, 10)}, "limit": {strconv.Itoa(limit)}}
+	if err := n.get(ctx, "/v2/farcaster/user/channels", query, &result); err != nil {
+		return nil, err
+	}
+	channels := make([]Channel, 0, len(result.Channels))
+	for _, c := range result.Channels {
+		channels = append(channels, Channel(c))
+	}
+	return channels, nil
+}
@@ -0,0 +1,105 @@
+package farcaster
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// stubServer serves the given JSON bodies by path and query, checking the
+// API key header if apiKey is set, and answers other requests with status
+// and notFound.
+func stubServer(t *testing.T, apiKey string, status int, notFound string, bodies map[string]string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if apiKey != "" && r.Header.Get("x-api-key") != apiKey {
+			http.Error(w, `{"code":"Unauthorized","message":"invalid API key"}`, http.StatusUnauthorized)
+			return
+		}
+		body, ok := bodies[r.URL.Path+"?"+r.URL.RawQuery]
+		if !ok {
+			http.Error(w, notFound, status)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestNeynar(t *testing.T) {
+	server := stubServer(t, "neynar-key", http.StatusNotFound, `{"code":"NotFound","message":"User not found"}`, map[string]string{
+		"/v2/farcaster/user/by_username?username=dwr": `{"user":{"fid":3,"username":"dwr.eth","display_name":"Dan Romero",
+			"profile":{"bio":{"text":"Working on Farcaster"}},"follower_count":318204,"following_count":2716,"score":0.99}}`,
+		"/v2/farcaster/user/by_username?username=empty": `{"user":null}`,
+		"/v2/farcaster/feed/user/casts?fid=3&limit=5": `{"casts":[
+			{"hash":"0x01","text":"gm","timestamp":"2024-09-02T14:03:11.000Z","channel":null},
+			{"hash":"0x02","text":"hi","timestamp":"2024-09-01T10:00:00.000Z","parent_url":"chain://eip155:1/erc721:0x01","channel":{"id":"farcaster"}}],
+			"next":{"cursor":null}}`,
+		"/v2/farcaster/user/channels?fid=3&limit=5": `{"channels":[{"id":"farcaster","name":"Farcaster","follower_count":98213,"url":"chain://eip155:1/erc721:0x01"}]}`,
+	})
+	provider := NewNeynar("neynar-key")
+	provider.BaseURL = server.URL
+
+	account, err := Lookup(context.Background(), provider, "dwr", 5)
+	if err != nil {
+		t.Fatalf("Lookup failed: %v", err)
+	}
+	if p := account.Profile; p.FID != 3 || p.Bio != "Working on Farcaster" || *p.FollowingCount != 2716 || *p.Score != 0.99 {
+		t.Errorf("Unexpected profile %+v", p)
+	}
+	want := time.Date(2024, 9, 2, 14, 3, 11, 0, time.UTC)
+	if c := account.Casts; len(c) != 2 || !c[0].Timestamp.Equal(want) || c[0].Channel != "" || c[1].Channel != "farcaster" {
+		t.Errorf("Unexpected casts %+v", c)
+	}
+	if c := account.Channels; len(c) != 1 || c[0].Name != "Farcaster" || c[0].FollowerCount != 98213 {
+		t.Errorf("Unexpected channels %+v", c)
+	}
+
+	if _, err := provider.Profile(context.Background(), "nobody"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+	// A response without a user is not an account with FID 0
+	if profile, err := provider.Profile(context.Background(), "empty"); !errors.Is(err, ErrSchema) {
+		t.Errorf("Expected ErrSchema, got %+v, %v", profile, err)
+	}
+	wrongKey := NewNeynar("wrong-key")
+	wrongKey.BaseURL = server.URL
+	if _, err := wrongKey.Casts(context.Background(), 3, 5); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Expected ErrUnauthorized, got %v", err)
+	}
+}
+
+func TestRESTRetries(t *testing.T) {
+	var attempts int
+	statuses := []int{http.StatusServiceUnavailable, http.StatusTooManyRequests}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if len(statuses) > 0 {
+			status := statuses[0]
+			statuses = statuses[1:]
+			http.Error(w, http.StatusText(status), status)
+			return
+		}
+		w.Write([]byte(`{"channels":[]}`))
+	}))
+	defer server.Close()
+	provider := NewNeynar("neynar-key")
+	provider.BaseURL = server.URL
+	provider.RetryBaseDelay = time.Millisecond
+
+	if channels, err := provider.Channels(context.Background(), 3, 5); err != nil || len(channels) != 0 || attempts != 3 {
+		t.Errorf("Expected success on the third attempt, got %v after %d", err, attempts)
+	}
+
+	// Client errors are not retried
+	attempts = 0
+	statuses = []int{http.StatusBadRequest}
+	var status *StatusError
+	if _, err := provider.Channels(context.Background(), 3, 5); !errors.As(err, &status) || attempts != 1 {
+		t.Errorf("Expected a StatusError after 1 attempt, got %v after %d", err, attempts)
+	}
+}
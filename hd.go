@@ -0,0 +1,332 @@
+// hd.go
+
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/tyler-smith/go-bip39"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// DefaultHDPath is the BIP-44 path used by Ethereum wallets, with "i"
+// standing for the account index.
+const DefaultHDPath = "m/44'/60'/0'/0/i"
+
+// HDAccount is an account derived from a mnemonic.
+type HDAccount struct {
+	Path       string
+	Address    common.Address
+	PrivateKey *ecdsa.PrivateKey
+}
+
+// GenerateMnemonic returns a new BIP-39 English mnemonic of the given number
+// of words (12, 15, 18, 21 or 24).
+func GenerateMnemonic(words int) (string, error) {
+	if words < 12 || words > 24 || words%3 != 0 {
+		return "", fmt.Errorf("word count must be 12, 15, 18, 21 or 24")
+	}
+	entropy, err := bip39.NewEntropy(words / 3 * 32)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate entropy: %v", err)
+	}
+	return bip39.NewMnemonic(entropy)
+}
+
+// DeriveAccounts derives count accounts starting at index start from a
+// BIP-39 mnemonic and optional passphrase. Every "i" component of
+// pathTemplate is replaced with the account index; a template without one
+// has the index appended.
+func DeriveAccounts(mnemonic, passphrase, pathTemplate string, start, count int) ([]HDAccount, error) {
+	mnemonic = strings.Join(strings.Fields(mnemonic), " ")
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mnemonic: %v", err)
+	}
+	if count <= 0 {
+		return nil, fmt.Errorf("number of accounts must be positive")
+	}
+	if strings.TrimSpace(pathTemplate) == "" {
+		pathTemplate = DefaultHDPath
+	}
+
+	master, chainCode, err := masterKey(seed)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]HDAccount, 0, count)
+	for i := start; i < start+count; i++ {
+		path, err := accounts.ParseDerivationPath(expandHDPath(pathTemplate, i))
+		if err != nil {
+			return nil, fmt.Errorf("invalid derivation path: %v", err)
+		}
+		key, err := deriveKey(master, chainCode, path)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, HDAccount{
+			Path:       path.String(),
+			Address:    crypto.PubkeyToAddress(key.PublicKey),
+			PrivateKey: key,
+		})
+	}
+	return result, nil
+}
+
+// expandHDPath substitutes index into a derivation path template.
+func expandHDPath(template string, index int) string {
+	components := strings.Split(strings.TrimSpace(template), "/")
+	replaced := false
+	for j, component := range components {
+		if component == "i" || component == "i'" {
+			components[j] = strconv.Itoa(index) + strings.TrimPrefix(component, "i")
+			replaced = true
+		}
+	}
+	if !replaced {
+		components = append(components, strconv.Itoa(index))
+	}
+	return strings.Join(components, "/")
+}
+
+// masterKey computes the BIP-32 master private key and chain code of seed.
+func masterKey(seed []byte) (*big.Int, []byte, error) {
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+
+	key := new(big.Int).SetBytes(sum[:32])
+	if key.Sign() == 0 || key.Cmp(secp256k1N) >= 0 {
+		return nil, nil, fmt.Errorf("seed produces an invalid master key")
+	}
+	return key, sum[32:], nil
+}
+
+// deriveKey walks path from the master key using BIP-32 private parent to
+// private child derivation.
+func deriveKey(key *big.Int, chainCode []byte, path accounts.DerivationPath) (*ecdsa.PrivateKey, error) {
+	for _, index := range path {
+		var data []byte
+		if index >= 0x80000000 {
+			// Hardened: 0x00 ‖ ser256(k) ‖ ser32(i)
+			data = append([]byte{0}, common.LeftPadBytes(key.Bytes(), 32)...)
+		} else {
+			// Normal: serP(point(k)) ‖ ser32(i)
+			privateKey, err := crypto.ToECDSA(common.LeftPadBytes(key.Bytes(), 32))
+			if err != nil {
+				return nil, err
+			}
+			data = crypto.CompressPubkey(&privateKey.PublicKey)
+		}
+		data = binary.BigEndian.AppendUint32(data, index)
+
+		mac := hmac.New(sha512.New, chainCode)
+		mac.Write(data)
+		sum := mac.Sum(nil)
+
+		tweak := new(big.Int).SetBytes(sum[:32])
+		if tweak.Cmp(secp256k1N) >= 0 {
+			return nil, fmt.Errorf("invalid child key at index %d", index)
+		}
+		key = new(big.Int).Add(tweak, key)
+		key.Mod(key, secp256k1N)
+		if key.Sign() == 0 {
+			return nil, fmt.Errorf("invalid child key at index %d", index)
+		}
+		chainCode = sum[32:]
+	}
+	return crypto.ToECDSA(common.LeftPadBytes(key.Bytes(), 32))
+}
+
+func init() {
+	registerTool(40, "Generate/Import HD Wallet (BIP-39)", newHDScreen)
+}
+
+// hdFormFields are the labels of the fields on the HD wallet form.
+var hdFormFields = []string{
+	"Mnemonic (blank to generate new)",
+	"Word Count (12 or 24)",
+	"Passphrase (optional)",
+	"Derivation Path",
+	"Number of Accounts",
+}
+
+const (
+	hdFieldMnemonic = iota
+	hdFieldWords
+	hdFieldPassphrase
+	hdFieldPath
+	hdFieldCount
+)
+
+// newHDForm returns the initial values of the HD wallet form.
+func newHDForm() []string {
+	values := make([]string, len(hdFormFields))
+	values[hdFieldWords] = "12"
+	values[hdFieldPath] = DefaultHDPath
+	values[hdFieldCount] = "5"
+	return values
+}
+
+// hdScreen generates or imports a mnemonic and derives accounts from it.
+type hdScreen struct {
+	screenID
+	session *session
+	form    form
+	content string
+}
+
+func newHDScreen(s *session) Screen {
+	return hdScreen{screenID: newScreenID(), session: s, form: newForm(hdFormFields, newHDForm(), hdFieldMnemonic, hdFieldPassphrase)}
+}
+
+func (h hdScreen) Init() tea.Cmd {
+	return nil
+}
+
+func (h hdScreen) Update(msg tea.Msg) (Screen, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.Type {
+		case tea.KeyCtrlC, tea.KeyEsc:
+			return nil, nil
+		case tea.KeyEnter:
+			count, err := strconv.Atoi(strings.TrimSpace(h.form.values[hdFieldCount]))
+			if err != nil || count <= 0 || count > 100 {
+				h.content = "Error: Number of accounts must be between 1 and 100."
+				return h, nil
+			}
+
+			secretMnemonic := h.form.secret(hdFieldMnemonic)
+			defer secretMnemonic.Wipe()
+			passphrase := h.form.secret(hdFieldPassphrase)
+			defer passphrase.Wipe()
+
+			// The BIP-39 libraries only take strings
+			mnemonic := strings.TrimSpace(string(secretMnemonic))
+			generated := mnemonic == ""
+			if generated {
+				words, err := strconv.Atoi(strings.TrimSpace(h.form.values[hdFieldWords]))
+				if err != nil {
+					h.content = "Error: Invalid word count."
+					return h, nil
+				}
+				if mnemonic, err = GenerateMnemonic(words); err != nil {
+					h.content = fmt.Sprintf("Error generating mnemonic: %v", err)
+					return h, nil
+				}
+			}
+
+			derived, err := DeriveAccounts(mnemonic, string(passphrase), h.form.values[hdFieldPath], 0, count)
+			if err != nil {
+				h.content = fmt.Sprintf("Error deriving accounts: %v", err)
+				return h, nil
+			}
+
+			result := NewHDWalletResult(mnemonic, generated, derived)
+			for _, account := range derived {
+				wipeKey(account.PrivateKey)
+			}
+			return h.session.showResult(result), nil
+		default:
+			h.form = h.form.update(msg)
+		}
+	}
+	return h, nil
+}
+
+func (h hdScreen) wipe() {
+	h.form.wipe()
+}
+
+func (h hdScreen) View() string {
+	s := titleStyle.Render("Generate/Import HD Wallet (BIP-39)") + "\n\n"
+	s += "Fill in the fields (Tab/Shift+Tab to move), Enter to derive or Esc to cancel:\n\n"
+	s += h.form.view()
+	if h.content != "" {
+		s += "\n" + h.content
+	}
+	return s
+}
+
+// HDWalletResult is the accounts derived from a mnemonic. The mnemonic is
+// only included when it was generated.
+type HDWalletResult struct {
+	Mnemonic Secret            `json:"mnemonic,omitempty" yaml:"mnemonic,omitempty"`
+	Accounts []HDAccountResult `json:"accounts" yaml:"accounts"`
+}
+
+// HDAccountResult is an account derived from a mnemonic.
+type HDAccountResult struct {
+	Path       string `json:"path" yaml:"path"`
+	Address    string `json:"address" yaml:"address"`
+	PrivateKey Secret `json:"privateKey" yaml:"privateKey"`
+}
+
+// NewHDWalletResult converts the accounts derived from mnemonic.
+func NewHDWalletResult(mnemonic string, generated bool, derived []HDAccount) *HDWalletResult {
+	result := &HDWalletResult{Accounts: []HDAccountResult{}}
+	if generated {
+		result.Mnemonic = Secret(mnemonic)
+	}
+	for _, account := range derived {
+		result.Accounts = append(result.Accounts, HDAccountResult{
+			Path:       account.Path,
+			Address:    account.Address.Hex(),
+			PrivateKey: privateKeySecret(account.PrivateKey),
+		})
+	}
+	return result
+}
+
+// Text implements Result.
+func (r *HDWalletResult) Text() string {
+	var sb strings.Builder
+	if len(r.Mnemonic) > 0 {
+		sb.WriteString(fmt.Sprintf("New Mnemonic:\n%s\n\n", string(r.Mnemonic)))
+	}
+	sb.WriteString("Derived Accounts:\n")
+	for _, account := range r.Accounts {
+		sb.WriteString(fmt.Sprintf("%s\n  Address    : %s\n  Private Key: %s\n", account.Path, account.Address, string(account.PrivateKey)))
+	}
+	if len(r.Mnemonic) > 0 {
+		sb.WriteString("\nWARNING: Store this mnemonic securely. Anyone with it controls every derived account!")
+	}
+	return sb.String()
+}
+
+// CopyFields implements Copyable.
+func (r *HDWalletResult) CopyFields() []CopyField {
+	if len(r.Mnemonic) == 0 {
+		return nil
+	}
+	return []CopyField{{Label: "Mnemonic", Value: string(r.Mnemonic), Secret: true}}
+}
+
+// Secrets implements SecretResult.
+func (r *HDWalletResult) Secrets() []string {
+	var secrets []string
+	for _, account := range r.Accounts {
+		secrets = append(secrets, string(account.PrivateKey))
+	}
+	return secrets
+}
+
+func (r *HDWalletResult) wipe() {
+	r.Mnemonic.Wipe()
+	for _, account := range r.Accounts {
+		account.PrivateKey.Wipe()
+	}
+}
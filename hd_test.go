@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/tyler-smith/go-bip39"
+)
+
+const testMnemonic = "test test test test test test test test test test test junk"
+
+func TestDeriveAccounts(t *testing.T) {
+	derived, err := DeriveAccounts(testMnemonic, "", DefaultHDPath, 0, 2)
+	if err != nil {
+		t.Fatalf("DeriveAccounts failed: %v", err)
+	}
+
+	expected := []struct {
+		path, address, key string
+	}{
+		{"m/44'/60'/0'/0/0", "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266", "ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80"},
+		{"m/44'/60'/0'/0/1", "0x70997970C51812dc3A010C7d01b50e0d17dc79C8", "59c6995e998f97a5a0044966f0945389dc9e86dae88c7a8412f4603b6b78690d"},
+	}
+	if len(derived) != len(expected) {
+		t.Fatalf("Expected %d accounts, got %d", len(expected), len(derived))
+	}
+	for i, want := range expected {
+		if derived[i].Path != want.path {
+			t.Errorf("Account %d: expected path %s, got %s", i, want.path, derived[i].Path)
+		}
+		if derived[i].Address.Hex() != want.address {
+			t.Errorf("Account %d: expected address %s, got %s", i, want.address, derived[i].Address.Hex())
+		}
+		if key := hex.EncodeToString(crypto.FromECDSA(derived[i].PrivateKey)); key != want.key {
+			t.Errorf("Account %d: expected key %s, got %s", i, want.key, key)
+		}
+	}
+}
+
+func TestDeriveAccountsCustomPath(t *testing.T) {
+	// Ledger Live style paths vary the account component instead
+	derived, err := DeriveAccounts(testMnemonic, "", "m/44'/60'/i'/0/0", 0, 1)
+	if err != nil {
+		t.Fatalf("DeriveAccounts failed: %v", err)
+	}
+	if derived[0].Address.Hex() != "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266" {
+		t.Errorf("Unexpected address %s", derived[0].Address.Hex())
+	}
+
+	derived, err = DeriveAccounts(testMnemonic, "", "m/44'/60'/0'/0", 1, 1)
+	if err != nil {
+		t.Fatalf("DeriveAccounts failed: %v", err)
+	}
+	if derived[0].Path != "m/44'/60'/0'/0/1" {
+		t.Errorf("Expected index to be appended, got %s", derived[0].Path)
+	}
+}
+
+func TestDeriveAccountsPassphrase(t *testing.T) {
+	plain, err := DeriveAccounts(testMnemonic, "", DefaultHDPath, 0, 1)
+	if err != nil {
+		t.Fatalf("DeriveAccounts failed: %v", err)
+	}
+	protected, err := DeriveAccounts(testMnemonic, "secret", DefaultHDPath, 0, 1)
+	if err != nil {
+		t.Fatalf("DeriveAccounts failed: %v", err)
+	}
+	if plain[0].Address == protected[0].Address {
+		t.Error("Expected passphrase to change the derived address")
+	}
+}
+
+func TestDeriveAccountsInvalidMnemonic(t *testing.T) {
+	if _, err := DeriveAccounts("test test test test test test test test test test test test", "", DefaultHDPath, 0, 1); err == nil {
+		t.Error("Expected an error for a mnemonic with a bad checksum")
+	}
+}
+
+func TestGenerateMnemonic(t *testing.T) {
+	for _, words := range []int{12, 24} {
+		mnemonic, err := GenerateMnemonic(words)
+		if err != nil {
+			t.Fatalf("GenerateMnemonic(%d) failed: %v", words, err)
+		}
+		if n := len(strings.Fields(mnemonic)); n != words {
+			t.Errorf("Expected %d words, got %d", words, n)
+		}
+		if !bip39.IsMnemonicValid(mnemonic) {
+			t.Errorf("Generated mnemonic is invalid: %s", mnemonic)
+		}
+	}
+
+	if _, err := GenerateMnemonic(13); err == nil {
+		t.Error("Expected an error for an unsupported word count")
+	}
+}
+
+// decodeXprv decodes a Base58Check extended private key into its chain code
+// and private key.
+func decodeXprv(t *testing.T, xprv string) (chainCode, key []byte) {
+	t.Helper()
+	n := new(big.Int)
+	for _, c := range xprv {
+		i := strings.IndexRune(base58Alphabet, c)
+		if i < 0 {
+			t.Fatalf("Invalid Base58 in %s", xprv)
+		}
+		n.Mul(n, big.NewInt(58)).Add(n, big.NewInt(int64(i)))
+	}
+	if n.BitLen() > 82*8 {
+		t.Fatalf("Invalid extended private key %s", xprv)
+	}
+	data := n.FillBytes(make([]byte, 82))
+	check := sha256.Sum256(data[:78])
+	check = sha256.Sum256(check[:])
+	if !bytes.Equal(check[:4], data[78:]) || data[45] != 0 {
+		t.Fatalf("Invalid extended private key %s", xprv)
+	}
+	return data[13:45], data[46:78]
+}
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+func TestBIP32Vectors(t *testing.T) {
+	const h = 0x80000000
+	// Test vectors 1 to 3 of BIP-32
+	vectors := []struct {
+		seed string
+		keys []struct {
+			path accounts.DerivationPath
+			xprv string
+		}
+	}{
+		{"000102030405060708090a0b0c0d0e0f", []struct {
+			path accounts.DerivationPath
+			xprv string
+		}{
+			{nil, "xprv9s21ZrQH143K3QTDL4LXw2F7HEK3wJUD2nW2nRk4stbPy6cq3jPPqjiChkVvvNKmPGJxWUtg6LnF5kejMRNNU3TGtRBeJgk33yuGBxrMPHi"},
+			{accounts.DerivationPath{0 + h}, "xprv9uHRZZhk6KAJC1avXpDAp4MDc3sQKNxDiPvvkX8Br5ngLNv1TxvUxt4cV1rGL5hj6KCesnDYUhd7oWgT11eZG7XnxHrnYeSvkzY7d2bhkJ7"},
+			{accounts.DerivationPath{0 + h, 1}, "xprv9wTYmMFdV23N2TdNG573QoEsfRrWKQgWeibmLntzniatZvR9BmLnvSxqu53Kw1UmYPxLgboyZQaXwTCg8MSY3H2EU4pWcQDnRnrVA1xe8fs"},
+			{accounts.DerivationPath{0 + h, 1, 2 + h}, "xprv9z4pot5VBttmtdRTWfWQmoH1taj2axGVzFqSb8C9xaxKymcFzXBDptWmT7FwuEzG3ryjH4ktypQSAewRiNMjANTtpgP4mLTj34bhnZX7UiM"},
+			{accounts.DerivationPath{0 + h, 1, 2 + h, 2}, "xprvA2JDeKCSNNZky6uBCviVfJSKyQ1mDYahRjijr5idH2WwLsEd4Hsb2Tyh8RfQMuPh7f7RtyzTtdrbdqqsunu5Mm3wDvUAKRHSC34sJ7in334"},
+			{accounts.DerivationPath{0 + h, 1, 2 + h, 2, 1000000000}, "xprvA41z7zogVVwxVSgdKUHDy1SKmdb533PjDz7J6N6mV6uS3ze1ai8FHa8kmHScGpWmj4WggLyQjgPie1rFSruoUihUZREPSL39UNdE3BBDu76"},
+		}},
+		{"fffcf9f6f3f0edeae7e4e1dedbd8d5d2cfccc9c6c3c0bdbab7b4b1aeaba8a5a29f9c999693908d8a8784817e7b7875726f6c696663605d5a5754514e4b484542", []struct {
+			path accounts.DerivationPath
+			xprv string
+		}{
+			{nil, "xprv9s21ZrQH143K31xYSDQpPDxsXRTUcvj2iNHm5NUtrGiGG5e2DtALGdso3pGz6ssrdK4PFmM8NSpSBHNqPqm55Qn3LqFtT2emdEXVYsCzC2U"},
+			{accounts.DerivationPath{0}, "xprv9vHkqa6EV4sPZHYqZznhT2NPtPCjKuDKGY38FBWLvgaDx45zo9WQRUT3dKYnjwih2yJD9mkrocEZXo1ex8G81dwSM1fwqWpWkeS3v86pgKt"},
+			{accounts.DerivationPath{0, 2147483647 + h}, "xprv9wSp6B7kry3Vj9m1zSnLvN3xH8RdsPP1Mh7fAaR7aRLcQMKTR2vidYEeEg2mUCTAwCd6vnxVrcjfy2kRgVsFawNzmjuHc2YmYRmagcEPdU9"},
+			{accounts.DerivationPath{0, 2147483647 + h, 1}, "xprv9zFnWC6h2cLgpmSA46vutJzBcfJ8yaJGg8cX1e5StJh45BBciYTRXSd25UEPVuesF9yog62tGAQtHjXajPPdbRCHuWS6T8XA2ECKADdw4Ef"},
+			{accounts.DerivationPath{0, 2147483647 + h, 1, 2147483646 + h}, "xprvA1RpRA33e1JQ7ifknakTFpgNXPmW2YvmhqLQYMmrj4xJXXWYpDPS3xz7iAxn8L39njGVyuoseXzU6rcxFLJ8HFsTjSyQbLYnMpCqE2VbFWc"},
+			{accounts.DerivationPath{0, 2147483647 + h, 1, 2147483646 + h, 2}, "xprvA2nrNbFZABcdryreWet9Ea4LvTJcGsqrMzxHx98MMrotbir7yrKCEXw7nadnHM8Dq38EGfSh6dqA9QWTyefMLEcBYJUuekgW4BYPJcr9E7j"},
+		}},
+		// Leading zeros of the private key are kept
+		{"4b381541583be4423346c643850da4b320e46a87ae3d2a4e6da11eba819cd4acba45d239319ac14f863b8d5ab5a0d0c64d2e8a1e7d1457df2e5a3c51c73235be", []struct {
+			path accounts.DerivationPath
+			xprv string
+		}{
+			{nil, "xprv9s21ZrQH143K25QhxbucbDDuQ4naNntJRi4KUfWT7xo4EKsHt2QJDu7KXp1A3u7Bi1j8ph3EGsZ9Xvz9dGuVrtHHs7pXeTzjuxBrCmmhgC6"},
+			{accounts.DerivationPath{0 + h}, "xprv9uPDJpEQgRQfDcW7BkF7eTya6RPxXeJCqCJGHuCJ4GiRVLzkTXBAJMu2qaMWPrS7AANYqdq6vcBcBUdJCVVFceUvJFjaPdGZ2y9WACViL4L"},
+		}},
+	}
+
+	for i, vector := range vectors {
+		seed, _ := hex.DecodeString(vector.seed)
+		master, chainCode, err := masterKey(seed)
+		if err != nil {
+			t.Fatalf("Vector %d: masterKey failed: %v", i+1, err)
+		}
+		for _, want := range vector.keys {
+			wantChainCode, wantKey := decodeXprv(t, want.xprv)
+			if want.path == nil && !bytes.Equal(chainCode, wantChainCode) {
+				t.Errorf("Vector %d: expected master chain code %x, got %x", i+1, wantChainCode, chainCode)
+			}
+			privateKey, err := deriveKey(master, chainCode, want.path)
+			if err != nil {
+				t.Fatalf("Vector %d %s: deriveKey failed: %v", i+1, want.path, err)
+			}
+			if got := crypto.FromECDSA(privateKey); !bytes.Equal(got, wantKey) {
+				t.Errorf("Vector %d %s: expected key %x, got %x", i+1, want.path, wantKey, got)
+			}
+		}
+	}
+}
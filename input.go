@@ -0,0 +1,330 @@
+// input.go
+
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/runeutil"
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// multilineHeight is the number of rows shown by multiline inputs.
+const multilineHeight = 5
+
+// textInput is the text field shared by every screen. Single-line fields are
+// backed by a bubbles textinput and multiline fields by a textarea, so both
+// edit whole runes and support cursor movement, word deletion and bracketed
+// paste. Secret fields are masked until Ctrl+R reveals them.
+//
+// The cursor does not blink and reading the system clipboard is disabled, so
+// updating an input never produces a command.
+type textInput struct {
+	line      textinput.Model
+	area      textarea.Model
+	multiline bool
+
+	// Secret inputs keep what is typed in secret and only hand the
+	// textinput a mask of it, so the value stays in a buffer that Reset
+	// zeroes. Revealing the secret with Ctrl+R copies it; see View.
+	masked   bool
+	secret   []rune
+	revealed bool
+}
+
+// secretSanitizer cleans up typed and pasted secrets the way the textinput
+// cleans up its own input, so the mask and the secret stay the same length.
+var secretSanitizer = runeutil.NewSanitizer(runeutil.ReplaceTabs(" "), runeutil.ReplaceNewlines(" "))
+
+// newTextInput returns an empty single-line input.
+func newTextInput() textInput {
+	line := textinput.New()
+	line.Prompt = ""
+	line.TextStyle = inputStyle
+	line.KeyMap.Paste.SetEnabled(false)
+	line.Cursor.SetMode(cursor.CursorStatic)
+	line.Focus()
+	return textInput{line: line}
+}
+
+// newSecretInput returns an empty single-line input that masks what is
+// typed, for private keys, mnemonics and passwords.
+func newSecretInput() textInput {
+	t := newTextInput()
+	t.line.EchoMode = textinput.EchoPassword
+	t.masked = true
+	return t
+}
+
+// newMultilineInput returns an empty multiline input. Enter is left to the
+// screen to submit the value, so new lines are inserted with Ctrl+J or by
+// pasting text that contains them.
+func newMultilineInput() textInput {
+	area := textarea.New()
+	area.Prompt = ""
+	area.ShowLineNumbers = false
+	area.CharLimit = 0
+	area.MaxHeight = 0
+	area.SetWidth(80)
+	area.SetHeight(multilineHeight)
+	area.FocusedStyle.Text = inputStyle
+	area.FocusedStyle.CursorLine = inputStyle
+	area.KeyMap.InsertNewline = key.NewBinding(key.WithKeys("ctrl+j"))
+	area.KeyMap.Paste.SetEnabled(false)
+	area.Cursor.SetMode(cursor.CursorStatic)
+	area.Focus()
+	return textInput{area: area, multiline: true}
+}
+
+// Value returns the text in the input. Secret inputs only return their mask
+// and are read with Secret instead, which does not copy the value into a
+// string.
+func (t textInput) Value() string {
+	if t.multiline {
+		return t.area.Value()
+	}
+	return t.line.Value()
+}
+
+// Secret returns a copy of the text in the input, which the caller wipes
+// once it is done with it.
+func (t textInput) Secret() Secret {
+	if !t.masked {
+		return Secret(t.line.Value())
+	}
+	s := make(Secret, 0, len(t.secret)*utf8.UTFMax)
+	for _, r := range t.secret {
+		s = utf8.AppendRune(s, r)
+	}
+	return s
+}
+
+// SetValue replaces the text in the input and moves the cursor to the end.
+func (t *textInput) SetValue(s string) {
+	if t.multiline {
+		t.area.SetValue(s)
+		return
+	}
+	if t.masked {
+		clear(t.secret)
+		t.secret = secretSanitizer.Sanitize([]rune(s))
+		s = string(maskRunes(t.secret))
+	}
+	t.line.SetValue(s)
+	t.line.CursorEnd()
+}
+
+// Reset clears the input. The value of secret inputs is zeroed, including
+// in copies of the input that share it.
+func (t *textInput) Reset() {
+	if t.multiline {
+		t.area.Reset()
+		return
+	}
+	clear(t.secret)
+	t.secret = t.secret[:0]
+	t.revealed = false
+	t.line.Reset()
+}
+
+// Masked reports whether the input hides what is typed.
+func (t textInput) Masked() bool {
+	return t.masked
+}
+
+// Update applies a key press to the input.
+func (t textInput) Update(msg tea.KeyMsg) textInput {
+	switch {
+	case t.multiline:
+		t.area, _ = t.area.Update(msg)
+	case t.masked:
+		return t.updateSecret(msg)
+	default:
+		t.line, _ = t.line.Update(msg)
+	}
+	return t
+}
+
+// updateSecret applies a key press to a secret input. Typed runes, including
+// spaces, reach the textinput as a mask, and the edit it makes to the mask is
+// then made to the secret. Like any masked textinput, words are not told apart, so word
+// movement and deletion go to the start or end of the input.
+func (t textInput) updateSecret(msg tea.KeyMsg) textInput {
+	if msg.Type == tea.KeyCtrlR {
+		t.revealed = !t.revealed
+		return t
+	}
+
+	typed := secretSanitizer.Sanitize(slices.Clone(msg.Runes))
+	defer clear(typed)
+	if len(msg.Runes) > 0 {
+		keys := t.line.KeyMap
+		if msg.Alt && !key.Matches(msg, keys.WordBackward, keys.WordForward, keys.DeleteWordForward) {
+			// The textinput would insert the rune as is
+			return t
+		}
+		msg.Runes = maskRunes(typed)
+	}
+
+	before, pos := utf8.RuneCountInString(t.line.Value()), t.line.Position()
+	t.line, _ = t.line.Update(msg)
+	after := utf8.RuneCountInString(t.line.Value())
+	switch {
+	case after > before:
+		t.secret = insertRunes(t.secret, pos, typed[:after-before])
+	case after < before:
+		// Deletions end at the old cursor or start there
+		t.secret = deleteRunes(t.secret, min(pos, t.line.Position()), before-after)
+	}
+	return t
+}
+
+// maskRunes returns a mask of the same length as r.
+func maskRunes(r []rune) []rune {
+	return []rune(strings.Repeat("*", len(r)))
+}
+
+// insertRunes inserts r into buf at pos. When buf has to grow, the old
+// buffer is zeroed so no copy of a secret is left behind.
+func insertRunes(buf []rune, pos int, r []rune) []rune {
+	n := len(buf) + len(r)
+	if n > cap(buf) {
+		grown := make([]rune, len(buf), max(n, 2*cap(buf)))
+		copy(grown, buf)
+		clear(buf)
+		buf = grown
+	}
+	buf = buf[:n]
+	copy(buf[pos+len(r):], buf[pos:n-len(r)])
+	copy(buf[pos:], r)
+	return buf
+}
+
+// deleteRunes removes n runes from buf at pos and zeroes the freed space.
+func deleteRunes(buf []rune, pos, n int) []rune {
+	copy(buf[pos:], buf[pos+n:])
+	clear(buf[len(buf)-n:])
+	return buf[:len(buf)-n]
+}
+
+// View renders the input with its cursor. Multiline inputs are followed by
+// a reminder of how to start a new line and secret inputs by how to reveal
+// them.
+//
+// A revealed secret is rendered from a string copy of it. Strings cannot be
+// zeroed, so every frame drawn while the secret is revealed leaves copies on
+// the heap that Reset does not reach; only the masked view keeps the secret
+// in the buffer alone.
+func (t textInput) View() string {
+	switch {
+	case t.multiline:
+		return t.area.View() + "\n(Ctrl+J inserts a new line)"
+	case t.masked && t.revealed:
+		line := t.line
+		line.EchoMode = textinput.EchoNormal
+		line.SetValue(string(t.secret))
+		line.SetCursor(t.line.Position())
+		return line.View() + "  (Ctrl+R to hide)"
+	case t.masked:
+		return t.line.View() + "  (Ctrl+R to reveal)"
+	}
+	return t.line.View()
+}
+
+// form is a multi-field form. The field under the cursor is edited with a
+// textInput while the others show their values. Secret fields are masked
+// and keep their value in their own secret input rather than in values.
+type form struct {
+	labels  []string
+	values  []string
+	secrets map[int]textInput
+	cursor  int
+	input   textInput
+}
+
+// newForm returns a form with the given labels and initial values. The
+// fields at the secret indices are masked.
+func newForm(labels, values []string, secret ...int) form {
+	f := form{labels: labels, values: values, secrets: make(map[int]textInput)}
+	for _, i := range secret {
+		in := newSecretInput()
+		in.SetValue(values[i])
+		f.secrets[i] = in
+		f.values[i] = ""
+	}
+	return f.focus()
+}
+
+// focus loads the field under the cursor into the input.
+func (f form) focus() form {
+	if in, ok := f.secrets[f.cursor]; ok {
+		f.input = in
+		return f
+	}
+	f.input = newTextInput()
+	f.input.SetValue(f.values[f.cursor])
+	return f
+}
+
+// update handles field navigation and editing.
+func (f form) update(msg tea.KeyMsg) form {
+	switch msg.Type {
+	case tea.KeyTab, tea.KeyDown:
+		if f.cursor < len(f.values)-1 {
+			f.cursor++
+			f = f.focus()
+		}
+	case tea.KeyShiftTab, tea.KeyUp:
+		if f.cursor > 0 {
+			f.cursor--
+			f = f.focus()
+		}
+	default:
+		f.input = f.input.Update(msg)
+		if _, ok := f.secrets[f.cursor]; ok {
+			f.secrets[f.cursor] = f.input
+		} else {
+			f.values[f.cursor] = f.input.Value()
+		}
+	}
+	return f
+}
+
+// secret returns a copy of the secret field i, which the caller wipes once
+// it is done with it.
+func (f form) secret(i int) Secret {
+	return f.secrets[i].Secret()
+}
+
+// wipe zeroes the secret fields.
+func (f form) wipe() {
+	for i, in := range f.secrets {
+		in.Reset()
+		f.secrets[i] = in
+	}
+}
+
+// view renders the labelled fields.
+func (f form) view() string {
+	s := ""
+	for i, label := range f.labels {
+		cursor := " "
+		value := inputStyle.Render(f.values[i])
+		if in, ok := f.secrets[i]; ok {
+			value = inputStyle.Render(strings.Repeat("*", len(in.secret)))
+		}
+		if f.cursor == i {
+			cursor = ">"
+			value = f.input.View()
+		}
+		s += fmt.Sprintf("%s %-38s %s\n", cursor, label+":", value)
+	}
+	return s
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func typeInto(t textInput, keys ...tea.KeyMsg) textInput {
+	for _, k := range keys {
+		t = t.Update(k)
+	}
+	return t
+}
+
+func runes(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestTextInputEditing(t *testing.T) {
+	in := typeInto(newTextInput(), runes("héllo wörld"), tea.KeyMsg{Type: tea.KeyBackspace})
+	if in.Value() != "héllo wörl" {
+		t.Errorf("Expected backspace to remove a whole rune, got %q", in.Value())
+	}
+
+	in = typeInto(in, tea.KeyMsg{Type: tea.KeyCtrlW})
+	if in.Value() != "héllo " {
+		t.Errorf("Expected Ctrl+W to delete the last word, got %q", in.Value())
+	}
+
+	// Move the cursor left and insert in the middle
+	in = typeInto(in, tea.KeyMsg{Type: tea.KeyHome}, tea.KeyMsg{Type: tea.KeyRight}, runes("x"))
+	if in.Value() != "hxéllo " {
+		t.Errorf("Expected an insertion after the first rune, got %q", in.Value())
+	}
+
+	// Pasted newlines are flattened on single-line inputs
+	in.Reset()
+	in = typeInto(in, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a\nb"), Paste: true})
+	if in.Value() != "a b" {
+		t.Errorf("Expected pasted text on one line, got %q", in.Value())
+	}
+}
+
+func TestTextInputMultiline(t *testing.T) {
+	in := typeInto(newMultilineInput(), runes("line one"), tea.KeyMsg{Type: tea.KeyCtrlJ}, runes("line two"))
+	if in.Value() != "line one\nline two" {
+		t.Errorf("Expected two lines, got %q", in.Value())
+	}
+
+	in.Reset()
+	in = typeInto(in, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a\nb\nc"), Paste: true})
+	if in.Value() != "a\nb\nc" {
+		t.Errorf("Expected pasted lines to be kept, got %q", in.Value())
+	}
+}
+
+func TestSecretInputMasked(t *testing.T) {
+	in := typeInto(newSecretInput(), runes(cliTestKey))
+	if !in.Masked() || string(in.Secret()) != cliTestKey || in.Value() != strings.Repeat("*", len(cliTestKey)) {
+		t.Fatalf("Expected a masked input holding the key, got %q", in.Value())
+	}
+	if strings.Contains(in.View(), cliTestKey[:8]) || strings.Contains(in.line.Value(), cliTestKey[:8]) {
+		t.Error("Expected the key to be hidden in the view and kept out of the textinput")
+	}
+
+	in = typeInto(in, tea.KeyMsg{Type: tea.KeyCtrlR})
+	if !strings.Contains(in.View(), cliTestKey[:8]) {
+		t.Errorf("Expected Ctrl+R to reveal the key, got %q", in.View())
+	}
+}
+
+func TestSecretInputEditing(t *testing.T) {
+	in := typeInto(newSecretInput(), runes("correct horse"), tea.KeyMsg{Type: tea.KeyBackspace})
+	if string(in.Secret()) != "correct hors" {
+		t.Errorf("Expected backspace to remove the last rune, got %q", string(in.Secret()))
+	}
+
+	in = typeInto(in, tea.KeyMsg{Type: tea.KeyHome}, tea.KeyMsg{Type: tea.KeyRight}, runes("ü"), tea.KeyMsg{Type: tea.KeyDelete})
+	if string(in.Secret()) != "cürrect hors" {
+		t.Errorf("Expected edits in the middle, got %q", string(in.Secret()))
+	}
+
+	// Masked inputs do not give away word boundaries
+	in = typeInto(in, tea.KeyMsg{Type: tea.KeyEnd}, tea.KeyMsg{Type: tea.KeyCtrlW})
+	if len(in.Secret()) != 0 {
+		t.Errorf("Expected Ctrl+W to clear the input, got %q", string(in.Secret()))
+	}
+	in = typeInto(in, runes("again"))
+
+	// Spaces are typed as their own key and are masked like any other rune
+	in = typeInto(in, tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}, runes("more"))
+	if string(in.Secret()) != "again more" || in.line.Value() != "**********" {
+		t.Errorf("Expected a masked space, got %q (mask %q)", string(in.Secret()), in.line.Value())
+	}
+
+	// Copies of the input share the secret, and all of it is zeroed
+	copied := in
+	in.Reset()
+	for _, r := range copied.secret[:cap(copied.secret)] {
+		if r != 0 {
+			t.Fatalf("Expected the secret to be zeroed, got %q", string(copied.secret))
+		}
+	}
+}
+
+func TestFormSecretFields(t *testing.T) {
+	f := newForm(batchFormFields, newBatchForm(), batchFieldPrivateKey)
+	for f.cursor < batchFieldPrivateKey {
+		f = f.update(tea.KeyMsg{Type: tea.KeyTab})
+	}
+	f = f.update(runes(cliTestKey))
+	f = f.update(tea.KeyMsg{Type: tea.KeyTab})
+
+	if string(f.secret(batchFieldPrivateKey)) != cliTestKey || f.values[batchFieldPrivateKey] != "" {
+		t.Errorf("Expected the key to be kept out of the form values, got %q", f.values[batchFieldPrivateKey])
+	}
+	if strings.Contains(f.view(), cliTestKey[:8]) {
+		t.Error("Expected the key to be hidden in the form view")
+	}
+
+	f.wipe()
+	if len(f.secret(batchFieldPrivateKey)) != 0 {
+		t.Error("Expected wipe to clear the key")
+	}
+}
@@ -0,0 +1,226 @@
+// httpretry.go
+
+// Package httpretry sends HTTP requests with a timeout per attempt, and
+// retries them with exponential backoff after transient failures. It is
+// shared by the Airstack GraphQL client and the Farcaster REST clients.
+package httpretry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Defaults of the timeout and retry settings of a Policy.
+const (
+	DefaultTimeout        = 15 * time.Second
+	DefaultMaxRetries     = 3
+	DefaultRetryBaseDelay = 500 * time.Millisecond
+	DefaultMaxRetryDelay  = 10 * time.Second
+)
+
+// MaxBodySize is the largest response body read, so a misbehaving server
+// cannot make a client buffer without bound.
+const MaxBodySize = 16 << 20
+
+// maxErrorBodySize is how much of the body of a failed response is kept in
+// a StatusError, which ends up in error messages.
+const maxErrorBodySize = 512
+
+// Policy is how requests are timed out and retried.
+type Policy struct {
+	// Timeout bounds each attempt of a request; zero means no limit
+	// besides the context of the request.
+	Timeout time.Duration
+	// MaxRetries is how often a request is retried after a network error,
+	// a timed out attempt or a 429 or 5xx response.
+	MaxRetries int
+	// RetryBaseDelay is the delay before the first retry. It doubles with
+	// each retry up to MaxRetryDelay, with random jitter so clients do not
+	// retry in lockstep. A Retry-After header from the server takes
+	// precedence, but is capped at MaxRetryDelay too.
+	RetryBaseDelay time.Duration
+	MaxRetryDelay  time.Duration
+}
+
+// DefaultPolicy returns a Policy with the default settings.
+func DefaultPolicy() Policy {
+	return Policy{
+		Timeout:        DefaultTimeout,
+		MaxRetries:     DefaultMaxRetries,
+		RetryBaseDelay: DefaultRetryBaseDelay,
+		MaxRetryDelay:  DefaultMaxRetryDelay,
+	}
+}
+
+// StatusError is a response with an HTTP status other than 200 OK.
+type StatusError struct {
+	StatusCode int
+	Body       string
+	// RetryAfter is the delay asked for by the Retry-After header, if any.
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("API request failed with status code %d: %s", e.StatusCode, e.Body)
+}
+
+// Sentinels are the errors of a client that a StatusError is matched
+// against, one per status the client tells apart.
+type Sentinels struct {
+	Unauthorized error // 401 and 403
+	RateLimited  error // 429
+	NotFound     error // 404
+}
+
+// Matches reports whether target is the error of sentinels for the status
+// code. Clients call it from the Is method of their errors wrapping e.
+func (e *StatusError) Matches(target error, sentinels Sentinels) bool {
+	switch {
+	case target == nil:
+		return false
+	case target == sentinels.Unauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case target == sentinels.RateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case target == sentinels.NotFound:
+		return e.StatusCode == http.StatusNotFound
+	}
+	return false
+}
+
+// Do sends the requests made by newRequest until one gets a 200 OK response,
+// and returns its body. Transient failures are retried until ctx is done;
+// other responses are returned as a *StatusError.
+//
+// newRequest is called for every attempt, with the context of the attempt.
+func (p Policy) Do(ctx context.Context, client *http.Client, newRequest func(ctx context.Context) (*http.Request, error)) ([]byte, error) {
+	for attempt := 0; ; attempt++ {
+		body, err := p.do(ctx, client, newRequest)
+		if err == nil {
+			return body, nil
+		}
+		if attempt >= p.MaxRetries || !retryable(ctx, err) {
+			return nil, err
+		}
+		if err := sleep(ctx, p.Delay(attempt, err)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// do makes one attempt at a request and returns the response body.
+func (p Policy) do(ctx context.Context, client *http.Client, newRequest func(ctx context.Context) (*http.Request, error)) ([]byte, error) {
+	if p.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.Timeout)
+		defer cancel()
+	}
+
+	req, err := newRequest(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, MaxBodySize+1))
+	if err != nil {
+		return nil, fmt.Errorf("error reading response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &StatusError{
+			StatusCode: resp.StatusCode,
+			Body:       errorBody(body),
+			RetryAfter: ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		}
+	}
+	if len(body) > MaxBodySize {
+		return nil, fmt.Errorf("response body exceeds %d bytes", MaxBodySize)
+	}
+	return body, nil
+}
+
+// errorBody returns the start of the body of a failed response, cut to
+// maxErrorBodySize bytes of valid UTF-8.
+func errorBody(body []byte) string {
+	if len(body) <= maxErrorBodySize {
+		return string(body)
+	}
+	return strings.ToValidUTF8(string(body[:maxErrorBodySize]), "") + "..."
+}
+
+// retryable reports whether a failed attempt is worth retrying: the server
+// was overloaded or failed, or the attempt itself failed or timed out while
+// ctx is still live.
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var status *StatusError
+	if errors.As(err, &status) {
+		return status.StatusCode == http.StatusTooManyRequests || status.StatusCode >= 500
+	}
+	return true
+}
+
+// Delay returns how long to wait before retrying after the given attempt
+// failed with err.
+func (p Policy) Delay(attempt int, err error) time.Duration {
+	var status *StatusError
+	if errors.As(err, &status) && status.RetryAfter > 0 {
+		if p.MaxRetryDelay > 0 {
+			return min(status.RetryAfter, p.MaxRetryDelay)
+		}
+		return status.RetryAfter
+	}
+
+	delay := p.RetryBaseDelay << min(attempt, 30)
+	if p.MaxRetryDelay > 0 && (delay > p.MaxRetryDelay || delay <= 0) {
+		delay = p.MaxRetryDelay
+	}
+	// Equal jitter: wait between half and all of the delay
+	if half := delay / 2; half > 0 {
+		delay = half + rand.N(half)
+	}
+	return delay
+}
+
+// ParseRetryAfter parses a Retry-After header, which is either a number of
+// seconds or an HTTP date, into the delay from now. It returns zero when the
+// header is missing or invalid.
+func ParseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,180 @@
+package httpretry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// roundTripFunc serves requests of an http.Client without a network.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func response(status int, body string, header ...string) *http.Response {
+	resp := &http.Response{StatusCode: status, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(body))}
+	for i := 0; i+1 < len(header); i += 2 {
+		resp.Header.Set(header[i], header[i+1])
+	}
+	return resp
+}
+
+func TestDo(t *testing.T) {
+	policy := Policy{MaxRetries: 2, RetryBaseDelay: time.Millisecond}
+	newRequest := func(ctx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodGet, "http://example.com", nil)
+	}
+
+	tests := []struct {
+		statuses []int
+		attempts int
+		err      int
+	}{
+		{[]int{http.StatusOK}, 1, 0},
+		{[]int{http.StatusTooManyRequests, http.StatusBadGateway, http.StatusOK}, 3, 0},
+		{[]int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable}, 3, http.StatusServiceUnavailable},
+		{[]int{http.StatusUnauthorized}, 1, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		var attempts int
+		client := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			attempts++
+			return response(tt.statuses[attempts-1], "body"), nil
+		})}
+
+		body, err := policy.Do(context.Background(), client, newRequest)
+		if attempts != tt.attempts {
+			t.Errorf("%v: expected %d attempts, got %d", tt.statuses, tt.attempts, attempts)
+		}
+		var status *StatusError
+		switch {
+		case tt.err == 0 && (err != nil || string(body) != "body"):
+			t.Errorf("%v: expected the body, got %q, %v", tt.statuses, body, err)
+		case tt.err != 0 && (!errors.As(err, &status) || status.StatusCode != tt.err):
+			t.Errorf("%v: expected a status error %d, got %v", tt.statuses, tt.err, err)
+		}
+	}
+}
+
+func TestDoBodyLimits(t *testing.T) {
+	policy := Policy{}
+	newRequest := func(ctx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodGet, "http://example.com", nil)
+	}
+	serve := func(status int, body string) (*http.Client, *int) {
+		var read int
+		return &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			resp := response(status, "")
+			resp.Body = io.NopCloser(countingReader{strings.NewReader(body), &read})
+			return resp, nil
+		})}, &read
+	}
+
+	// Error bodies are cut short for error messages
+	client, _ := serve(http.StatusBadRequest, strings.Repeat("é", maxErrorBodySize))
+	_, err := policy.Do(context.Background(), client, newRequest)
+	var status *StatusError
+	if !errors.As(err, &status) || len(status.Body) > maxErrorBodySize+3 || !strings.HasSuffix(status.Body, "é...") {
+		t.Errorf("Expected the error body to be cut to %d bytes of UTF-8, got %v", maxErrorBodySize, err)
+	}
+
+	// Oversized responses are not read to the end
+	client, read := serve(http.StatusOK, strings.Repeat("x", MaxBodySize+1024))
+	if _, err := policy.Do(context.Background(), client, newRequest); err == nil || *read > MaxBodySize+1 {
+		t.Errorf("Expected an error after reading at most %d bytes, got %v after %d", MaxBodySize+1, err, *read)
+	}
+}
+
+// countingReader counts the bytes read from r into n.
+type countingReader struct {
+	r io.Reader
+	n *int
+}
+
+func (c countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	*c.n += n
+	return n, err
+}
+
+func TestDoTimeout(t *testing.T) {
+	policy := Policy{Timeout: 20 * time.Millisecond, MaxRetries: 1, RetryBaseDelay: time.Millisecond}
+	var attempts int
+	client := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		// Hang until the attempt is given up on
+		attempts++
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	})}
+	newRequest := func(ctx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodGet, "http://example.com", nil)
+	}
+
+	if _, err := policy.Do(context.Background(), client, newRequest); !errors.Is(err, context.DeadlineExceeded) || attempts != 2 {
+		t.Errorf("Expected both attempts to time out, got %v after %d", err, attempts)
+	}
+}
+
+func TestDelay(t *testing.T) {
+	policy := Policy{RetryBaseDelay: 100 * time.Millisecond, MaxRetryDelay: time.Second}
+	for attempt, want := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second} {
+		if d := policy.Delay(attempt, errors.New("connection reset")); d < want/2 || d > want {
+			t.Errorf("Attempt %d: expected a delay between %s and %s, got %s", attempt, want/2, want, d)
+		}
+	}
+
+	// Retry-After takes precedence, up to MaxRetryDelay
+	retryAfter := &StatusError{StatusCode: http.StatusServiceUnavailable, RetryAfter: 300 * time.Millisecond}
+	if d := policy.Delay(3, retryAfter); d != retryAfter.RetryAfter {
+		t.Errorf("Expected a delay of %s, got %s", retryAfter.RetryAfter, d)
+	}
+	retryAfter.RetryAfter = time.Hour
+	if d := policy.Delay(0, retryAfter); d != policy.MaxRetryDelay {
+		t.Errorf("Expected a delay of %s, got %s", policy.MaxRetryDelay, d)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := map[string]time.Duration{
+		"":                              0,
+		"3":                             3 * time.Second,
+		"soon":                          0,
+		"Mon, 01 Jan 2024 12:00:30 GMT": 30 * time.Second,
+		"Mon, 01 Jan 2024 11:00:00 GMT": 0,
+	}
+	for value, want := range tests {
+		if got := ParseRetryAfter(value, now); got != want {
+			t.Errorf("ParseRetryAfter(%q) = %s, want %s", value, got, want)
+		}
+	}
+}
+
+func TestMatches(t *testing.T) {
+	sentinels := Sentinels{Unauthorized: errors.New("unauthorized"), RateLimited: errors.New("rate limited"), NotFound: errors.New("not found")}
+	tests := map[int]error{
+		http.StatusUnauthorized:    sentinels.Unauthorized,
+		http.StatusForbidden:       sentinels.Unauthorized,
+		http.StatusTooManyRequests: sentinels.RateLimited,
+		http.StatusNotFound:        sentinels.NotFound,
+	}
+	for status, want := range tests {
+		err := &StatusError{StatusCode: status}
+		for _, target := range []error{sentinels.Unauthorized, sentinels.RateLimited, sentinels.NotFound, nil} {
+			if got := err.Matches(target, sentinels); got != (target == want) {
+				t.Errorf("Status %d: Matches(%v) = %v", status, target, got)
+			}
+		}
+	}
+
+	// Sentinels a client does not have match nothing
+	if (&StatusError{StatusCode: http.StatusNotFound}).Matches(nil, Sentinels{}) {
+		t.Error("Expected a nil target not to match")
+	}
+}
@@ -0,0 +1,490 @@
+// keystore.go
+
+package main
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/bits"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ParseScryptParams parses a scrypt strength: "standard" (geth's default,
+// N=262144 P=1), "light" (N=4096 P=6) or explicit "N:P" values.
+func ParseScryptParams(s string) (int, int, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "standard":
+		return keystore.StandardScryptN, keystore.StandardScryptP, nil
+	case "light":
+		return keystore.LightScryptN, keystore.LightScryptP, nil
+	}
+
+	nStr, pStr, ok := strings.Cut(s, ":")
+	if !ok {
+		return 0, 0, fmt.Errorf("scrypt strength must be standard, light or N:P")
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(nStr))
+	if err != nil || n < 2 || bits.OnesCount(uint(n)) != 1 {
+		return 0, 0, fmt.Errorf("scrypt N must be a power of two greater than 1")
+	}
+	p, err := strconv.Atoi(strings.TrimSpace(pStr))
+	if err != nil || p < 1 {
+		return 0, 0, fmt.Errorf("scrypt P must be a positive integer")
+	}
+	return n, p, nil
+}
+
+// EncryptKeystore encrypts privateKey with passphrase into a Web3 Secret
+// Storage (keystore V3) JSON document.
+func EncryptKeystore(privateKey *ecdsa.PrivateKey, passphrase string, scryptN, scryptP int) ([]byte, error) {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key id: %v", err)
+	}
+	key := &keystore.Key{
+		Id:         id,
+		Address:    crypto.PubkeyToAddress(privateKey.PublicKey),
+		PrivateKey: privateKey,
+	}
+	return keystore.EncryptKey(key, passphrase, scryptN, scryptP)
+}
+
+// DecryptKeystore unlocks a keystore V3 JSON document with passphrase.
+func DecryptKeystore(keyJSON []byte, passphrase string) (*ecdsa.PrivateKey, error) {
+	key, err := keystore.DecryptKey(keyJSON, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt keystore: %v", err)
+	}
+	return key.PrivateKey, nil
+}
+
+// ChangeKeystorePassword re-encrypts a keystore V3 JSON document under a new
+// passphrase, keeping its address and id.
+func ChangeKeystorePassword(keyJSON []byte, oldPassphrase, newPassphrase string, scryptN, scryptP int) ([]byte, error) {
+	key, err := keystore.DecryptKey(keyJSON, oldPassphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt keystore: %v", err)
+	}
+	defer wipeKey(key.PrivateKey)
+	return keystore.EncryptKey(key, newPassphrase, scryptN, scryptP)
+}
+
+// UnlockKeystoreFile reads and decrypts the keystore file at path.
+func UnlockKeystoreFile(path, passphrase string) (*ecdsa.PrivateKey, error) {
+	keyJSON, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore file: %v", err)
+	}
+	return DecryptKeystore(keyJSON, passphrase)
+}
+
+// WriteKeystoreFile writes keyJSON into dir using geth's
+// UTC--<timestamp>--<address> file naming and returns the file's path.
+func WriteKeystoreFile(dir string, address common.Address, keyJSON []byte) (string, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create keystore directory: %v", err)
+	}
+	ts := time.Now().UTC()
+	name := fmt.Sprintf("UTC--%04d-%02d-%02dT%02d-%02d-%02d.%09dZ--%x",
+		ts.Year(), ts.Month(), ts.Day(), ts.Hour(), ts.Minute(), ts.Second(), ts.Nanosecond(), address[:])
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, keyJSON, 0600); err != nil {
+		return "", fmt.Errorf("failed to write keystore file: %v", err)
+	}
+	return path, nil
+}
+
+// replaceFile atomically replaces the contents of path.
+func replaceFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func init() {
+	registerTool(50, "Keystore (Web3 Secret Storage)", newKeystoreScreen)
+}
+
+// KeystoreMode selects what the keystore screen does.
+type KeystoreMode int
+
+const (
+	KeystoreEncrypt KeystoreMode = iota
+	KeystoreDecrypt
+	KeystoreChangePassword
+)
+
+// String returns a human readable name for the keystore mode.
+func (k KeystoreMode) String() string {
+	switch k {
+	case KeystoreEncrypt:
+		return "Encrypt Private Key"
+	case KeystoreDecrypt:
+		return "Unlock Keystore for Signing"
+	case KeystoreChangePassword:
+		return "Change Password"
+	default:
+		return fmt.Sprintf("KeystoreMode(%d)", int(k))
+	}
+}
+
+// next returns the mode that follows k when cycling through them.
+func (k KeystoreMode) next() KeystoreMode {
+	return (k + 1) % 3
+}
+
+// fields returns the form labels of the keystore mode.
+func (k KeystoreMode) fields() []string {
+	switch k {
+	case KeystoreDecrypt:
+		return []string{"Keystore File", "Passphrase"}
+	case KeystoreChangePassword:
+		return []string{"Keystore File", "Current Passphrase", "New Passphrase", "Confirm New Passphrase", "Scrypt Strength (standard/light/N:P)"}
+	default:
+		return []string{"Private Key (hex)", "Passphrase", "Confirm Passphrase", "Scrypt Strength (standard/light/N:P)", "Output Directory"}
+	}
+}
+
+// secretFields returns the indices of the form fields of the keystore mode
+// that hold a private key or passphrase.
+func (k KeystoreMode) secretFields() []int {
+	switch k {
+	case KeystoreDecrypt:
+		return []int{1}
+	case KeystoreChangePassword:
+		return []int{1, 2, 3}
+	default:
+		return []int{0, 1, 2}
+	}
+}
+
+// newKeystoreForm returns the initial values of the keystore form for mode.
+func newKeystoreForm(mode KeystoreMode) []string {
+	values := make([]string, len(mode.fields()))
+	switch mode {
+	case KeystoreEncrypt:
+		values[3] = "standard"
+		values[4] = "keystore"
+	case KeystoreChangePassword:
+		values[4] = "standard"
+	}
+	return values
+}
+
+// unlockedKey is a private key unlocked from a keystore file. It stays in
+// the session so the signing tools can use it without the key ever being
+// shown or typed.
+type unlockedKey struct {
+	key     *ecdsa.PrivateKey
+	address common.Address
+	path    string
+}
+
+// unlock makes key the unlocked key of the session, wiping the previous one.
+func (s *session) unlock(key *unlockedKey) {
+	s.lock()
+	s.unlocked = key
+}
+
+// lock wipes the unlocked key of the session, if any.
+func (s *session) lock() {
+	if s.unlocked != nil {
+		wipeKey(s.unlocked.key)
+		s.unlocked = nil
+	}
+}
+
+// errNoSigningKey means no private key was typed and no keystore is unlocked.
+var errNoSigningKey = errors.New("private key cannot be empty")
+
+// signingKeyError is the message the signing screens show when signingKey
+// fails.
+func signingKeyError(err error) string {
+	if errors.Is(err, errNoSigningKey) {
+		return "Error: Private key cannot be empty."
+	}
+	return "Error: Invalid private key format."
+}
+
+// signingKey returns the private key typed into a secret field, or a copy of
+// the unlocked keystore key when the field is empty. Callers wipe the key.
+func (s *session) signingKey(typed Secret) (*ecdsa.PrivateKey, error) {
+	if len(typed) > 0 {
+		return typed.PrivateKey()
+	}
+	if s.unlocked == nil {
+		return nil, errNoSigningKey
+	}
+	keyBytes := crypto.FromECDSA(s.unlocked.key)
+	defer clear(keyBytes)
+	return crypto.ToECDSA(keyBytes)
+}
+
+// unlockedHint tells the signing tools' users that they can leave the
+// private key empty, if a keystore is unlocked.
+func (s *session) unlockedHint() string {
+	if s.unlocked == nil {
+		return ""
+	}
+	return fmt.Sprintf("Leave the private key empty to sign with the unlocked keystore account %s.\n", s.unlocked.address.Hex())
+}
+
+// keystoreScreen encrypts and decrypts keystore files and changes their
+// passwords. Scrypt takes seconds, so the work runs in a command and the
+// screen ignores keys while it does.
+type keystoreScreen struct {
+	screenID
+	session *session
+	mode    KeystoreMode
+	form    form
+	working bool
+	content string
+}
+
+// keystoreDoneMsg carries the outcome of the work of the keystore screen,
+// and the key unlocked by decrypting a keystore.
+type keystoreDoneMsg struct {
+	result   *KeystoreResult
+	unlocked *unlockedKey
+	err      error
+}
+
+// KeystoreResult is the outcome of encrypting, unlocking or changing the
+// password of a keystore file. Address is empty when the password changed.
+type KeystoreResult struct {
+	Operation string `json:"operation" yaml:"operation"`
+	Path      string `json:"path" yaml:"path"`
+	Address   string `json:"address,omitempty" yaml:"address,omitempty"`
+}
+
+// Text implements Result.
+func (r *KeystoreResult) Text() string {
+	switch r.Operation {
+	case "unlock":
+		return fmt.Sprintf("Unlocked %s from %s.\n\n"+
+			"Leave the private key empty in Sign Message, Sign/Verify Typed Data, Sign-In with Ethereum and Sign Transaction to sign with it. "+
+			"It stays unlocked until you quit or press Ctrl+L on the keystore screen.", r.Address, r.Path)
+	case "change-password":
+		return fmt.Sprintf("Password of %s changed.", r.Path)
+	default:
+		return fmt.Sprintf("Keystore File   : %s\nEthereum Address: %s\n\nThe key can only be recovered with its passphrase. Do not lose it!", r.Path, r.Address)
+	}
+}
+
+// CopyFields implements Copyable.
+func (r *KeystoreResult) CopyFields() []CopyField {
+	fields := []CopyField{{Label: "Path", Value: r.Path}}
+	if r.Address != "" {
+		fields = append(fields, CopyField{Label: "Address", Value: r.Address})
+	}
+	return fields
+}
+
+func newKeystoreScreen(s *session) Screen {
+	return keystoreScreen{screenID: newScreenID(), session: s}.withMode(KeystoreEncrypt)
+}
+
+// withMode switches to mode with an empty form, wiping the secrets entered
+// in the previous one.
+func (k keystoreScreen) withMode(mode KeystoreMode) keystoreScreen {
+	k.form.wipe()
+	k.mode = mode
+	k.form = newForm(mode.fields(), newKeystoreForm(mode), mode.secretFields()...)
+	k.content = ""
+	return k
+}
+
+func (k keystoreScreen) Init() tea.Cmd {
+	return nil
+}
+
+func (k keystoreScreen) Update(msg tea.Msg) (Screen, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if k.working {
+			return k, nil
+		}
+		switch msg.Type {
+		case tea.KeyCtrlC, tea.KeyEsc:
+			return nil, nil
+		case tea.KeyCtrlT:
+			k = k.withMode(k.mode.next())
+		case tea.KeyCtrlL:
+			if k.session.unlocked != nil {
+				k.session.lock()
+				k.content = "Keystore account locked."
+			}
+		case tea.KeyEnter:
+			k.working = true
+			k.content = "Working... scrypt takes a few seconds."
+			return k, k.run()
+		default:
+			k.form = k.form.update(msg)
+		}
+	case keystoreDoneMsg:
+		k.working = false
+		if msg.err != nil {
+			k.content = fmt.Sprintf("Error: %v", msg.err)
+			return k, nil
+		}
+		if msg.unlocked != nil {
+			k.session.unlock(msg.unlocked)
+		}
+		return k.session.showResult(msg.result), nil
+	}
+	return k, nil
+}
+
+// run returns the command doing the work of the current mode.
+func (k keystoreScreen) run() tea.Cmd {
+	return func() tea.Msg {
+		switch k.mode {
+		case KeystoreDecrypt:
+			unlocked, err := k.enterDecryptKeystore()
+			if err != nil {
+				return keystoreDoneMsg{err: err}
+			}
+			result := &KeystoreResult{Operation: "unlock", Path: unlocked.path, Address: unlocked.address.Hex()}
+			return keystoreDoneMsg{result: result, unlocked: unlocked}
+		case KeystoreChangePassword:
+			result, err := k.enterChangeKeystorePassword()
+			return keystoreDoneMsg{result: result, err: err}
+		default:
+			result, err := k.enterEncryptKeystore()
+			return keystoreDoneMsg{result: result, err: err}
+		}
+	}
+}
+
+func (k keystoreScreen) enterEncryptKeystore() (*KeystoreResult, error) {
+	key := k.form.secret(0)
+	defer key.Wipe()
+	passphrase, confirm := k.form.secret(1), k.form.secret(2)
+	defer passphrase.Wipe()
+	defer confirm.Wipe()
+
+	privateKey, err := key.PrivateKey()
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key format")
+	}
+	defer wipeKey(privateKey)
+	if len(passphrase) == 0 {
+		return nil, fmt.Errorf("passphrase cannot be empty")
+	}
+	if !bytes.Equal(passphrase, confirm) {
+		return nil, fmt.Errorf("passphrases do not match")
+	}
+	scryptN, scryptP, err := ParseScryptParams(k.form.values[3])
+	if err != nil {
+		return nil, err
+	}
+	dir := strings.TrimSpace(k.form.values[4])
+	if dir == "" {
+		return nil, fmt.Errorf("output directory cannot be empty")
+	}
+
+	keyJSON, err := EncryptKeystore(privateKey, string(passphrase), scryptN, scryptP)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt key: %v", err)
+	}
+	address := crypto.PubkeyToAddress(privateKey.PublicKey)
+	path, err := WriteKeystoreFile(dir, address, keyJSON)
+	if err != nil {
+		return nil, err
+	}
+	return &KeystoreResult{Operation: "encrypt", Path: path, Address: address.Hex()}, nil
+}
+
+// enterDecryptKeystore unlocks the keystore file for the signing tools.
+func (k keystoreScreen) enterDecryptKeystore() (*unlockedKey, error) {
+	path := strings.TrimSpace(k.form.values[0])
+	if path == "" {
+		return nil, fmt.Errorf("keystore file cannot be empty")
+	}
+	passphrase := k.form.secret(1)
+	defer passphrase.Wipe()
+	privateKey, err := UnlockKeystoreFile(path, string(passphrase))
+	if err != nil {
+		return nil, err
+	}
+	return &unlockedKey{key: privateKey, address: crypto.PubkeyToAddress(privateKey.PublicKey), path: path}, nil
+}
+
+func (k keystoreScreen) enterChangeKeystorePassword() (*KeystoreResult, error) {
+	path := strings.TrimSpace(k.form.values[0])
+	if path == "" {
+		return nil, fmt.Errorf("keystore file cannot be empty")
+	}
+	oldPassphrase, passphrase, confirm := k.form.secret(1), k.form.secret(2), k.form.secret(3)
+	defer oldPassphrase.Wipe()
+	defer passphrase.Wipe()
+	defer confirm.Wipe()
+	if len(passphrase) == 0 {
+		return nil, fmt.Errorf("new passphrase cannot be empty")
+	}
+	if !bytes.Equal(passphrase, confirm) {
+		return nil, fmt.Errorf("new passphrases do not match")
+	}
+	scryptN, scryptP, err := ParseScryptParams(k.form.values[4])
+	if err != nil {
+		return nil, err
+	}
+
+	keyJSON, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore file: %v", err)
+	}
+	updated, err := ChangeKeystorePassword(keyJSON, string(oldPassphrase), string(passphrase), scryptN, scryptP)
+	if err != nil {
+		return nil, err
+	}
+	if err := replaceFile(path, updated); err != nil {
+		return nil, fmt.Errorf("failed to write keystore file: %v", err)
+	}
+	return &KeystoreResult{Operation: "change-password", Path: path}, nil
+}
+
+func (k keystoreScreen) wipe() {
+	k.form.wipe()
+}
+
+func (k keystoreScreen) View() string {
+	s := titleStyle.Render("Keystore (Web3 Secret Storage)") + "\n\n"
+	s += fmt.Sprintf("Mode: %s (press Ctrl+T to switch)\n\n", menuStyle.Render(k.mode.String()))
+	if u := k.session.unlocked; u != nil {
+		s += fmt.Sprintf("Unlocked: %s (press Ctrl+L to lock)\n\n", u.address.Hex())
+	}
+	s += "Fill in the fields (Tab/Shift+Tab to move), Enter to confirm or Esc to cancel:\n\n"
+	s += k.form.view()
+	if k.content != "" {
+		s += "\n" + k.content
+	}
+	return s
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Test vector from the Web3 Secret Storage Definition.
+const testKeystoreJSON = `{"crypto":{"cipher":"aes-128-ctr","cipherparams":{"iv":"6087dab2f9fdbbfaddc31a909735c1e6"},"ciphertext":"5318b4d5bcd28de64ee5559e671353e16f075ecae9f99c7a79a38af5f869aa46","kdf":"pbkdf2","kdfparams":{"c":262144,"dklen":32,"prf":"hmac-sha256","salt":"ae3cd4e7013836a3df6bd7241b12db061dbe2c6785853cce422d148a624ce0bd"},"mac":"517ead924a9d0dc3124507e3393d175ce3ff7c1e96529c6c555ce9e51205e9b2"},"id":"3198bc9c-6672-5ab3-d995-4942343ae5b6","version":3}`
+
+func TestDecryptKeystore(t *testing.T) {
+	privateKey, err := DecryptKeystore([]byte(testKeystoreJSON), "testpassword")
+	if err != nil {
+		t.Fatalf("DecryptKeystore failed: %v", err)
+	}
+	expected := "7a28b5ba57c53603b0b07b56bba752f7784bf506fa95edc395f5cf6c7514fe9d"
+	if key := hex.EncodeToString(crypto.FromECDSA(privateKey)); key != expected {
+		t.Errorf("Expected key %s, got %s", expected, key)
+	}
+
+	if _, err := DecryptKeystore([]byte(testKeystoreJSON), "wrongpassword"); err == nil {
+		t.Error("Expected an error for a wrong passphrase")
+	}
+}
+
+func TestKeystoreRoundTrip(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	keyJSON, err := EncryptKeystore(privateKey, "first", keystore.LightScryptN, keystore.LightScryptP)
+	if err != nil {
+		t.Fatalf("EncryptKeystore failed: %v", err)
+	}
+
+	dir := t.TempDir()
+	address := crypto.PubkeyToAddress(privateKey.PublicKey)
+	path, err := WriteKeystoreFile(dir, address, keyJSON)
+	if err != nil {
+		t.Fatalf("WriteKeystoreFile failed: %v", err)
+	}
+	if name := filepath.Base(path); !strings.HasPrefix(name, "UTC--") || !strings.HasSuffix(name, hex.EncodeToString(address[:])) {
+		t.Errorf("Unexpected keystore file name %s", name)
+	}
+
+	unlocked, err := UnlockKeystoreFile(path, "first")
+	if err != nil {
+		t.Fatalf("UnlockKeystoreFile failed: %v", err)
+	}
+	if !unlocked.Equal(privateKey) {
+		t.Error("Unlocked key does not match the original")
+	}
+
+	updated, err := ChangeKeystorePassword(keyJSON, "first", "second", keystore.LightScryptN, keystore.LightScryptP)
+	if err != nil {
+		t.Fatalf("ChangeKeystorePassword failed: %v", err)
+	}
+	if err := replaceFile(path, updated); err != nil {
+		t.Fatalf("replaceFile failed: %v", err)
+	}
+	if _, err := UnlockKeystoreFile(path, "first"); err == nil {
+		t.Error("Expected the old passphrase to be rejected")
+	}
+	unlocked, err = UnlockKeystoreFile(path, "second")
+	if err != nil {
+		t.Fatalf("UnlockKeystoreFile with new passphrase failed: %v", err)
+	}
+	if !unlocked.Equal(privateKey) {
+		t.Error("Key changed after changing the password")
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir failed: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Expected only the keystore file to remain, found %d entries", len(entries))
+	}
+}
+
+func TestParseScryptParams(t *testing.T) {
+	tests := []struct {
+		input   string
+		n, p    int
+		wantErr bool
+	}{
+		{"", keystore.StandardScryptN, keystore.StandardScryptP, false},
+		{"standard", keystore.StandardScryptN, keystore.StandardScryptP, false},
+		{"Light", keystore.LightScryptN, keystore.LightScryptP, false},
+		{"1024:2", 1024, 2, false},
+		{"1000:1", 0, 0, true},
+		{"1024:0", 0, 0, true},
+		{"strong", 0, 0, true},
+	}
+	for _, tt := range tests {
+		n, p, err := ParseScryptParams(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseScryptParams(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if n != tt.n || p != tt.p {
+			t.Errorf("ParseScryptParams(%q) = %d, %d, want %d, %d", tt.input, n, p, tt.n, tt.p)
+		}
+	}
+}
+
+func TestKeystoreUnlockForSigning(t *testing.T) {
+	privateKey, err := crypto.HexToECDSA(cliTestKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyJSON, err := EncryptKeystore(privateKey, "secret", keystore.LightScryptN, keystore.LightScryptP)
+	if err != nil {
+		t.Fatalf("EncryptKeystore failed: %v", err)
+	}
+	path, err := WriteKeystoreFile(t.TempDir(), crypto.PubkeyToAddress(privateKey.PublicKey), keyJSON)
+	if err != nil {
+		t.Fatalf("WriteKeystoreFile failed: %v", err)
+	}
+
+	s := &session{}
+	screen := newKeystoreScreen(s)
+	screen, _ = screen.Update(tea.KeyMsg{Type: tea.KeyCtrlT})
+	screen, _ = screen.Update(runes(path))
+	screen, _ = screen.Update(tea.KeyMsg{Type: tea.KeyTab})
+	screen, _ = screen.Update(runes("secret"))
+	screen, cmd := screen.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil || !screen.(keystoreScreen).working {
+		t.Fatal("Expected Enter to unlock the keystore in the background")
+	}
+	// Keys are ignored while scrypt runs
+	if next, _ := screen.Update(tea.KeyMsg{Type: tea.KeyEsc}); next == nil {
+		t.Error("Expected Esc to be ignored while unlocking")
+	}
+
+	screen, _ = screen.Update(cmd())
+	view := screen.View()
+	if s.unlocked == nil || !strings.Contains(view, "Unlocked "+cliTestAddress) || strings.Contains(view, cliTestKey) {
+		t.Fatalf("Expected the key to be unlocked without showing it, got:\n%s", view)
+	}
+
+	// The signing tools use the unlocked key when none is typed
+	screen = newSignScreen(s)
+	if !strings.Contains(screen.View(), "unlocked keystore account "+cliTestAddress) {
+		t.Errorf("Expected the sign screen to offer the unlocked key, got:\n%s", screen.View())
+	}
+	screen, _ = screen.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	screen, _ = screen.Update(runes("hello"))
+	screen, _ = screen.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	want, err := signMessageWithKey(privateKey, "hello", SchemeEIP191)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(screen.View(), want) {
+		t.Errorf("Expected a signature by the unlocked key, got:\n%s", screen.View())
+	}
+
+	s.lock()
+	if s.unlocked != nil {
+		t.Error("Expected lock to drop the unlocked key")
+	}
+}
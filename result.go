@@ -0,0 +1,325 @@
+// result.go
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"example.com/ethgotools/farcaster"
+	"gopkg.in/yaml.v3"
+)
+
+// OutputFormat selects how results are rendered.
+type OutputFormat int
+
+const (
+	// OutputText is the human readable text shown by the TUI.
+	OutputText OutputFormat = iota
+	// OutputJSON is indented JSON.
+	OutputJSON
+	// OutputYAML is YAML.
+	OutputYAML
+)
+
+// ParseOutputFormat parses an output format name: text, json or yaml.
+func ParseOutputFormat(s string) (OutputFormat, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "text":
+		return OutputText, nil
+	case "json":
+		return OutputJSON, nil
+	case "yaml", "yml":
+		return OutputYAML, nil
+	default:
+		return OutputText, fmt.Errorf("unknown output format %q (want text, json or yaml)", s)
+	}
+}
+
+// String returns the name of the output format.
+func (f OutputFormat) String() string {
+	switch f {
+	case OutputText:
+		return "text"
+	case OutputJSON:
+		return "json"
+	case OutputYAML:
+		return "yaml"
+	default:
+		return fmt.Sprintf("OutputFormat(%d)", int(f))
+	}
+}
+
+// Set implements flag.Value.
+func (f *OutputFormat) Set(s string) error {
+	format, err := ParseOutputFormat(s)
+	if err != nil {
+		return err
+	}
+	*f = format
+	return nil
+}
+
+// next returns the format that follows f when cycling through them.
+func (f OutputFormat) next() OutputFormat {
+	return (f + 1) % 3
+}
+
+// Result is the structured outcome of an operation. Its fields are
+// serialized for JSON and YAML output and Text renders it for people.
+type Result interface {
+	Text() string
+}
+
+// SecretResult is implemented by results that contain secrets, such as
+// private keys or mnemonics, beyond the secret fields they let be copied.
+// The secrets are hidden until revealed and cleared from the clipboard after
+// being copied.
+type SecretResult interface {
+	Secrets() []string
+}
+
+// RenderResult renders r in the given format.
+func RenderResult(r Result, format OutputFormat) (string, error) {
+	switch format {
+	case OutputJSON:
+		data, err := json.MarshalIndent(r, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to encode JSON: %v", err)
+		}
+		return string(data), nil
+	case OutputYAML:
+		data, err := yaml.Marshal(r)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode YAML: %v", err)
+		}
+		return strings.TrimSuffix(string(data), "\n"), nil
+	default:
+		return r.Text(), nil
+	}
+}
+
+// AddressResult is the address derived from a private key.
+type AddressResult struct {
+	Address string `json:"address" yaml:"address"`
+}
+
+// Text implements Result.
+func (r *AddressResult) Text() string {
+	return fmt.Sprintf("Ethereum Address: %s", r.Address)
+}
+
+// CopyFields implements Copyable.
+func (r *AddressResult) CopyFields() []CopyField {
+	return []CopyField{{Label: "Address", Value: r.Address}}
+}
+
+// KeyResult is a newly generated private key.
+type KeyResult struct {
+	PrivateKey Secret `json:"privateKey" yaml:"privateKey"`
+	Address    string `json:"address" yaml:"address"`
+}
+
+// Text implements Result.
+func (r *KeyResult) Text() string {
+	return fmt.Sprintf("New Private Key: %s\nCorresponding Ethereum Address: %s\n\nWARNING: Store this private key securely. Never share it with anyone!", string(r.PrivateKey), r.Address)
+}
+
+// CopyFields implements Copyable.
+func (r *KeyResult) CopyFields() []CopyField {
+	return []CopyField{
+		{Label: "Private Key", Value: string(r.PrivateKey), Secret: true},
+		{Label: "Address", Value: r.Address},
+	}
+}
+
+func (r *KeyResult) wipe() {
+	r.PrivateKey.Wipe()
+}
+
+// SignatureResult is a message signature.
+type SignatureResult struct {
+	Scheme    string `json:"scheme" yaml:"scheme"`
+	Signature string `json:"signature" yaml:"signature"`
+}
+
+// Text implements Result.
+func (r *SignatureResult) Text() string {
+	return fmt.Sprintf("Signature:\n%s", r.Signature)
+}
+
+// CopyFields implements Copyable.
+func (r *SignatureResult) CopyFields() []CopyField {
+	return []CopyField{{Label: "Signature", Value: r.Signature}}
+}
+
+// VerificationResult is the outcome of verifying a message signature.
+// SignerType is only set when the signature was checked on-chain.
+type VerificationResult struct {
+	Valid      bool   `json:"valid" yaml:"valid"`
+	Address    string `json:"address" yaml:"address"`
+	Scheme     string `json:"scheme" yaml:"scheme"`
+	SignerType string `json:"signerType,omitempty" yaml:"signerType,omitempty"`
+}
+
+// Text implements Result.
+func (r *VerificationResult) Text() string {
+	status := "invalid"
+	if r.Valid {
+		status = "valid"
+	}
+	if r.SignerType != "" {
+		return fmt.Sprintf("Signature is %s (%s).", status, r.SignerType)
+	}
+	return fmt.Sprintf("Signature is %s.", status)
+}
+
+// FarcasterProfile is the profile of a Farcaster account. The counts and
+// the score are null when the provider does not have them.
+type FarcasterProfile struct {
+	FID            uint64   `json:"fid" yaml:"fid"`
+	ProfileName    string   `json:"profileName" yaml:"profileName"`
+	DisplayName    string   `json:"displayName,omitempty" yaml:"displayName,omitempty"`
+	Bio            string   `json:"bio,omitempty" yaml:"bio,omitempty"`
+	FollowerCount  *int     `json:"followerCount" yaml:"followerCount"`
+	FollowingCount *int     `json:"followingCount" yaml:"followingCount"`
+	Score          *float64 `json:"score" yaml:"score"`
+}
+
+// FarcasterCast is a cast published by a Farcaster account.
+type FarcasterCast struct {
+	Text      string `json:"text" yaml:"text"`
+	Hash      string `json:"hash" yaml:"hash"`
+	Timestamp string `json:"timestamp,omitempty" yaml:"timestamp,omitempty"`
+	Channel   string `json:"channel,omitempty" yaml:"channel,omitempty"`
+}
+
+// FarcasterChannel is a channel a Farcaster account takes part in.
+type FarcasterChannel struct {
+	ID            string `json:"id" yaml:"id"`
+	Name          string `json:"name" yaml:"name"`
+	FollowerCount int    `json:"followerCount" yaml:"followerCount"`
+	URL           string `json:"url,omitempty" yaml:"url,omitempty"`
+}
+
+// FarcasterResult is the profile, recent casts and channels of a Farcaster
+// account. Channels is null when the provider does not know them.
+type FarcasterResult struct {
+	Username string             `json:"username" yaml:"username"`
+	Provider string             `json:"provider" yaml:"provider"`
+	Profile  FarcasterProfile   `json:"profile" yaml:"profile"`
+	Casts    []FarcasterCast    `json:"casts" yaml:"casts"`
+	Channels []FarcasterChannel `json:"channels" yaml:"channels"`
+}
+
+// NewFarcasterResult converts an account looked up as fname with provider.
+func NewFarcasterResult(fname, provider string, account *farcaster.Account) *FarcasterResult {
+	p := account.Profile
+	result := &FarcasterResult{
+		Username: fname,
+		Provider: provider,
+		Profile: FarcasterProfile{
+			FID:            p.FID,
+			ProfileName:    p.Username,
+			DisplayName:    p.DisplayName,
+			Bio:            p.Bio,
+			FollowerCount:  p.FollowerCount,
+			FollowingCount: p.FollowingCount,
+			Score:          p.Score,
+		},
+		Casts: []FarcasterCast{},
+	}
+	for _, cast := range account.Casts {
+		c := FarcasterCast{Text: cast.Text, Hash: cast.Hash, Channel: cast.Channel}
+		if !cast.Timestamp.IsZero() {
+			c.Timestamp = cast.Timestamp.UTC().Format(time.RFC3339)
+		}
+		result.Casts = append(result.Casts, c)
+	}
+	if account.Channels != nil {
+		result.Channels = []FarcasterChannel{}
+		for _, channel := range account.Channels {
+			result.Channels = append(result.Channels, FarcasterChannel{
+				ID:            channel.ID,
+				Name:          channel.Name,
+				FollowerCount: channel.FollowerCount,
+				URL:           channel.URL,
+			})
+		}
+	}
+	return result
+}
+
+// Text implements Result.
+func (r *FarcasterResult) Text() string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("Results for Farcaster user '%s' from %s:\n\n", r.Username, r.Provider))
+
+	p := r.Profile
+	count := func(n *int) string {
+		if n == nil {
+			return "unknown"
+		}
+		return fmt.Sprint(*n)
+	}
+	sb.WriteString("Profile Information:\n")
+	sb.WriteString(fmt.Sprintf("FID            : %d\n", p.FID))
+	sb.WriteString(fmt.Sprintf("Profile Name   : %s\n", p.ProfileName))
+	if p.DisplayName != "" {
+		sb.WriteString(fmt.Sprintf("Display Name   : %s\n", p.DisplayName))
+	}
+	if p.Bio != "" {
+		sb.WriteString(fmt.Sprintf("Bio            : %s\n", p.Bio))
+	}
+	sb.WriteString(fmt.Sprintf("Follower Count : %s\n", count(p.FollowerCount)))
+	sb.WriteString(fmt.Sprintf("Following Count: %s\n", count(p.FollowingCount)))
+	if p.Score != nil {
+		sb.WriteString(fmt.Sprintf("Score          : %.2f\n", *p.Score))
+	}
+	sb.WriteString("\n")
+
+	if len(r.Casts) > 0 {
+		sb.WriteString("Recent Casts:\n")
+		for i, cast := range r.Casts {
+			sb.WriteString(fmt.Sprintf("%d. %s", i+1, cast.Text))
+			if cast.Channel != "" {
+				sb.WriteString(fmt.Sprintf(" (in %s)", channelName(cast.Channel)))
+			}
+			sb.WriteString("\n")
+		}
+	} else {
+		sb.WriteString("No recent casts found.\n")
+	}
+	sb.WriteString("\n")
+
+	switch {
+	case r.Channels == nil:
+		sb.WriteString(fmt.Sprintf("Channels are not available from %s.\n", r.Provider))
+	case len(r.Channels) == 0:
+		sb.WriteString("No channels found.\n")
+	default:
+		sb.WriteString("Channels:\n")
+		for i, channel := range r.Channels {
+			sb.WriteString(fmt.Sprintf("%d. %s (%s, %d followers)\n", i+1, channelName(channel.ID), channel.Name, channel.FollowerCount))
+		}
+	}
+
+	return sb.String()
+}
+
+// channelName returns how channels are referred to on Farcaster, such as
+// /memes, or the URL of a cast's parent that is not a channel.
+func channelName(channel string) string {
+	if strings.Contains(channel, "://") {
+		return channel
+	}
+	return "/" + channel
+}
+
+// resultMsg carries the Result of an asynchronous operation to the TUI.
+type resultMsg struct {
+	result Result
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"example.com/ethgotools/farcaster"
+	"gopkg.in/yaml.v3"
+)
+
+func TestRenderResult(t *testing.T) {
+	result := &VerificationResult{Valid: true, Address: cliTestAddress, Scheme: "eip191", SignerType: "EOA"}
+
+	text, err := RenderResult(result, OutputText)
+	if err != nil || text != "Signature is valid (EOA)." {
+		t.Errorf("Unexpected text output %q (%v)", text, err)
+	}
+
+	out, err := RenderResult(result, OutputJSON)
+	if err != nil {
+		t.Fatalf("RenderResult JSON failed: %v", err)
+	}
+	var decoded VerificationResult
+	if err := json.Unmarshal([]byte(out), &decoded); err != nil || decoded != *result {
+		t.Errorf("JSON output %s does not round-trip (%v)", out, err)
+	}
+
+	out, err = RenderResult(result, OutputYAML)
+	if err != nil {
+		t.Fatalf("RenderResult YAML failed: %v", err)
+	}
+	decoded = VerificationResult{}
+	if err := yaml.Unmarshal([]byte(out), &decoded); err != nil || decoded != *result {
+		t.Errorf("YAML output %s does not round-trip (%v)", out, err)
+	}
+}
+
+func TestParseOutputFormat(t *testing.T) {
+	for input, want := range map[string]OutputFormat{"": OutputText, "TEXT": OutputText, "json": OutputJSON, "yml": OutputYAML} {
+		if got, err := ParseOutputFormat(input); err != nil || got != want {
+			t.Errorf("ParseOutputFormat(%q) = %v, %v; want %v", input, got, err, want)
+		}
+	}
+	if _, err := ParseOutputFormat("xml"); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
+
+func TestFarcasterResult(t *testing.T) {
+	followers, following, score := 10, 2, 1.5
+	account := &farcaster.Account{
+		Profile: farcaster.Profile{FID: 5650, Username: "vitalik", FollowerCount: &followers, FollowingCount: &following, Score: &score},
+		Casts: []farcaster.Cast{
+			{Text: "gm", Hash: "0x01", Timestamp: time.Date(2024, 9, 2, 14, 3, 11, 0, time.UTC)},
+			{Text: "hi", Hash: "0x02", Channel: "ethereum"},
+		},
+		Channels: []farcaster.Channel{{ID: "ethereum", Name: "Ethereum", FollowerCount: 1200}},
+	}
+
+	result := NewFarcasterResult("vitalik", "Airstack", account)
+	if *result.Profile.FollowerCount != 10 || len(result.Casts) != 2 || result.Casts[0].Timestamp != "2024-09-02T14:03:11Z" {
+		t.Fatalf("Unexpected result %+v", result)
+	}
+	text := result.Text()
+	for _, want := range []string{"Results for Farcaster user 'vitalik' from Airstack", "FID            : 5650",
+		"Follower Count : 10", "Score          : 1.50", "1. gm\n", "2. hi (in /ethereum)", "1. /ethereum (Ethereum, 1200 followers)"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected text output to contain %q, got:\n%s", want, text)
+		}
+	}
+
+	// Hubs know neither counts nor channels
+	empty := NewFarcasterResult("nobody", "Farcaster Hub", &farcaster.Account{Profile: farcaster.Profile{FID: 1}})
+	out, err := RenderResult(empty, OutputJSON)
+	if err != nil || !strings.Contains(out, `"followerCount": null`) || !strings.Contains(out, `"casts": []`) || !strings.Contains(out, `"channels": null`) {
+		t.Errorf("Unexpected JSON for an empty result: %s (%v)", out, err)
+	}
+	text = empty.Text()
+	for _, want := range []string{"Follower Count : unknown", "No recent casts found.", "Channels are not available from Farcaster Hub."} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected text output to contain %q, got:\n%s", want, text)
+		}
+	}
+}
+
+func TestToolResults(t *testing.T) {
+	inspection, err := InspectSignature(eip2098Vectors[0].standard)
+	if err != nil {
+		t.Fatalf("InspectSignature failed: %v", err)
+	}
+	nonce := uint64(1)
+	valid := false
+	results := map[Result]string{
+		inspection: "Format           : Standard (65 bytes)",
+		&TypedDataResult{Digest: "0x01", Signature: "0x02", Valid: &valid}:                   "Signature is invalid.",
+		&SIWESignatureResult{Message: "example.com wants you to sign in", Signature: "0x02"}: "Signature:\n0x02",
+		&SignedTxResult{Type: "EIP-1559 (type 2)", Hash: "0x03", Raw: "0x04"}:                "Raw Transaction:\n0x04",
+		&ContractAddressResult{Opcode: "CREATE", Deployer: cliTestAddress, Nonce: &nonce}:    "Nonce           : 1",
+		&KeystoreResult{Operation: "change-password", Path: "key.json"}:                      "Password of key.json changed.",
+	}
+	for result, want := range results {
+		if text := result.Text(); !strings.Contains(text, want) {
+			t.Errorf("Expected the text of %T to contain %q, got:\n%s", result, want, text)
+		}
+		for _, format := range []OutputFormat{OutputJSON, OutputYAML} {
+			if out, err := RenderResult(result, format); err != nil || out == "" {
+				t.Errorf("Failed to render %T as %s: %q (%v)", result, format, out, err)
+			}
+		}
+	}
+
+	// JSON keeps the structure of the result rather than its text
+	out, _ := RenderResult(inspection, OutputJSON)
+	var decoded SignatureInspection
+	if err := json.Unmarshal([]byte(out), &decoded); err != nil || decoded != *inspection {
+		t.Errorf("JSON output %s does not round-trip (%v)", out, err)
+	}
+}
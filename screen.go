@@ -0,0 +1,79 @@
+// screen.go
+
+package main
+
+import (
+	"cmp"
+	"io"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Screen is a page of the TUI. Every tool is a Screen that keeps its own
+// state, so nothing entered in one tool is visible to another.
+//
+// Update returns the screen to show next: the receiver to stay on it,
+// another screen (such as one returned by showResult) to move on, or nil to
+// go back to the menu.
+type Screen interface {
+	Init() tea.Cmd
+	Update(msg tea.Msg) (Screen, tea.Cmd)
+	View() string
+}
+
+// session is the state shared by the screens for the lifetime of the TUI.
+// width and height are the size of the terminal, or zero until it is known.
+type session struct {
+	outputFormat OutputFormat
+	width        int
+	height       int
+
+	clipboard      io.Writer // the output of the TUI, for OSC 52
+	clipboardClear time.Duration
+	copies         int
+
+	unlocked *unlockedKey // set by the keystore screen
+}
+
+// newSession returns the session of a TUI writing to out.
+func newSession(out io.Writer) *session {
+	return &session{clipboard: out, clipboardClear: clipboardClearDelay()}
+}
+
+// terminalOutput is the output of the TUI. The renderer writes every frame
+// with a single Write from its own goroutine, so writes are serialized to
+// keep escape sequences the screens write, such as OSC 52, from landing in
+// the middle of a frame. It embeds the file so the program still sees a
+// terminal.
+type terminalOutput struct {
+	*os.File
+	mu sync.Mutex
+}
+
+func (t *terminalOutput) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.File.Write(p)
+}
+
+// tool is an entry of the main menu.
+type tool struct {
+	order int
+	title string
+	open  func(*session) Screen
+}
+
+// tools are the registered tools in menu order.
+var tools []tool
+
+// registerTool adds a tool to the main menu. Tools are listed by ascending
+// order; the built-in ones use multiples of 10 so others can go in between.
+// open is called each time the tool is selected and returns its first screen.
+func registerTool(order int, title string, open func(*session) Screen) {
+	tools = append(tools, tool{order: order, title: title, open: open})
+	slices.SortStableFunc(tools, func(a, b tool) int { return cmp.Compare(a.order, b.order) })
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"example.com/ethgotools/airstack/airstacktest"
+)
+
+func sendKeys(t *testing.T, m model, keys ...tea.KeyMsg) model {
+	t.Helper()
+	for _, k := range keys {
+		next, _ := m.Update(k)
+		m = next.(model)
+	}
+	return m
+}
+
+func TestMenuListsRegisteredTools(t *testing.T) {
+	view := initialModel(io.Discard).View()
+	last := -1
+	for _, tool := range tools {
+		i := strings.Index(view, tool.title)
+		if i < last {
+			t.Errorf("Expected %q to be listed in menu order", tool.title)
+		}
+		last = i
+	}
+	if len(tools) == 0 || !strings.HasPrefix(tools[0].title, "Convert") || !strings.Contains(view, "Quit") {
+		t.Errorf("Unexpected menu:\n%s", view)
+	}
+}
+
+func TestScreenNavigation(t *testing.T) {
+	enter := tea.KeyMsg{Type: tea.KeyEnter}
+	esc := tea.KeyMsg{Type: tea.KeyEsc}
+
+	// Convert a key and return to the menu from the result
+	m := sendKeys(t, initialModel(io.Discard), enter, runes(cliTestKey), enter)
+	if !strings.Contains(m.View(), cliTestAddress) {
+		t.Fatalf("Expected the address on the result screen, got:\n%s", m.View())
+	}
+	m = sendKeys(t, m, enter)
+	if m.screen != nil {
+		t.Fatal("Expected Enter on the result screen to return to the menu")
+	}
+
+	// Input typed into a tool is gone once it is closed and reopened
+	m = sendKeys(t, m, enter, runes("abc"), esc, enter)
+	if value := m.screen.(convertScreen).key.Value(); value != "" {
+		t.Errorf("Expected an empty input after reopening, got %q", value)
+	}
+	m = sendKeys(t, m, esc)
+
+	// Keys typed into a tool are zeroed when it is closed
+	m = sendKeys(t, m, enter, runes(cliTestKey))
+	typed := m.screen.(convertScreen).key.secret
+	m = sendKeys(t, m, esc)
+	if strings.Trim(string(typed), "\x00") != "" {
+		t.Errorf("Expected the key to be wiped, got %q", string(typed))
+	}
+
+	// The output format chosen on a result screen is kept for the session
+	m = sendKeys(t, m, enter, runes(cliTestKey), enter, tea.KeyMsg{Type: tea.KeyTab}, enter, enter, runes(cliTestKey), enter)
+	if !strings.Contains(m.View(), `"address": "`+cliTestAddress+`"`) {
+		t.Errorf("Expected JSON output to be kept, got:\n%s", m.View())
+	}
+}
+
+func TestFarcasterLookupCancel(t *testing.T) {
+	t.Setenv("FARCASTER_PROVIDER", "airstack")
+	t.Setenv("AIRSTACK_API_KEY", "test-key")
+	t.Setenv("AIRSTACK_TIMEOUT", "")
+	screen := newFarcasterScreen(&session{})
+	screen, _ = screen.Update(runes("dwr"))
+	screen, cmd := screen.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	lookup := screen.(farcasterScreen).lookup
+	if cmd == nil || lookup == nil {
+		t.Fatal("Expected Enter to start a request")
+	}
+
+	// Esc cancels the request instead of leaving the screen
+	screen, _ = screen.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if screen == nil || !strings.Contains(screen.View(), "Request cancelled.") {
+		t.Fatalf("Expected to stay on the screen with the request cancelled, got %v", screen)
+	}
+
+	// An answer that arrives afterwards is ignored
+	screen, _ = screen.Update(farcasterResultMsg{lookup: lookup, message: "late"})
+	if strings.Contains(screen.View(), "late") {
+		t.Error("Expected the answer of a cancelled request to be ignored")
+	}
+	if next, _ := screen.Update(tea.KeyMsg{Type: tea.KeyEsc}); next != nil {
+		t.Error("Expected a second Esc to return to the menu")
+	}
+}
+
+func TestVerifyOnChainCancel(t *testing.T) {
+	t.Setenv("ETH_RPC_URL", "http://127.0.0.1:1")
+	enter := tea.KeyMsg{Type: tea.KeyEnter}
+	screen := newVerifyScreen(&session{})
+	screen, _ = screen.Update(runes("hi"))
+	screen, _ = screen.Update(enter)
+	screen, _ = screen.Update(runes("0x01"))
+	screen, _ = screen.Update(enter)
+	screen, _ = screen.Update(runes(cliTestAddress))
+	screen, cmd := screen.Update(enter)
+	check := screen.(verifyScreen).check
+	if cmd == nil || check == nil {
+		t.Fatal("Expected Enter to start an on-chain check")
+	}
+
+	// Enter does not start a second check while one is running
+	if _, cmd := screen.Update(enter); cmd != nil {
+		t.Error("Expected Enter to be ignored during a check")
+	}
+
+	// Esc cancels the check instead of leaving the screen
+	screen, _ = screen.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if screen == nil || !strings.Contains(screen.View(), "Verification cancelled.") {
+		t.Fatalf("Expected to stay on the screen with the check cancelled, got %v", screen)
+	}
+
+	// An answer that arrives afterwards is ignored
+	screen, _ = screen.Update(verifyResultMsg{check: check, message: "late"})
+	if strings.Contains(screen.View(), "late") {
+		t.Error("Expected the answer of a cancelled check to be ignored")
+	}
+}
+
+func TestFarcasterLookup(t *testing.T) {
+	server := airstacktest.NewServer()
+	defer server.Close()
+	t.Setenv("FARCASTER_PROVIDER", "airstack")
+	t.Setenv("AIRSTACK_API_KEY", airstacktest.APIKey)
+	t.Setenv("AIRSTACK_URL", server.URL)
+
+	lookup := func(fname string) string {
+		screen := newFarcasterScreen(&session{})
+		screen, _ = screen.Update(runes(fname))
+		screen, cmd := screen.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		if cmd == nil {
+			t.Fatalf("Expected Enter to start a request for %q", fname)
+		}
+		screen, _ = screen.Update(cmd())
+		return screen.View()
+	}
+
+	view := lookup("dwr")
+	for _, want := range []string{"Results for Farcaster user 'dwr'", "Profile Name   : dwr.eth", "1. gm"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected the result to contain %q, got:\n%s", want, view)
+		}
+	}
+	if view := lookup("nobody"); !strings.Contains(view, "No data found") {
+		t.Errorf("Expected no data for an unknown name, got:\n%s", view)
+	}
+
+	// Blank names are rejected before any request
+	screen := newFarcasterScreen(&session{})
+	screen, _ = screen.Update(runes("  "))
+	screen, cmd := screen.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd != nil || !strings.Contains(screen.View(), "username cannot be empty") {
+		t.Errorf("Expected a blank name to be rejected, got:\n%s", screen.View())
+	}
+
+	t.Setenv("AIRSTACK_API_KEY", "wrong-key")
+	if view := lookup("dwr"); !strings.Contains(view, "check AIRSTACK_API_KEY") {
+		t.Errorf("Expected a hint about the API key, got:\n%s", view)
+	}
+}
+
+// handoverScreen hands over to a fresh instance of itself on "n".
+type handoverScreen struct {
+	screenID
+	wiped *int
+}
+
+func (h handoverScreen) Init() tea.Cmd { return nil }
+
+func (h handoverScreen) Update(msg tea.Msg) (Screen, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok && msg.String() == "n" {
+		return handoverScreen{screenID: newScreenID(), wiped: h.wiped}, nil
+	}
+	return h, nil
+}
+
+func (h handoverScreen) View() string { return "" }
+
+func (h handoverScreen) wipe() { *h.wiped++ }
+
+func TestWipeOnHandover(t *testing.T) {
+	var wiped int
+	m := model{session: &session{}, screen: handoverScreen{screenID: newScreenID(), wiped: &wiped}}
+	m = sendKeys(t, m, runes("x"))
+	if wiped != 0 {
+		t.Fatal("Expected a screen staying open to keep its secrets")
+	}
+	sendKeys(t, m, runes("n"))
+	if wiped != 1 {
+		t.Fatal("Expected a screen to be wiped when a fresh one of the same type takes over")
+	}
+}
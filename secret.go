@@ -0,0 +1,89 @@
+// secret.go
+
+package main
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Secret is sensitive material such as a private key, mnemonic or passphrase.
+// It is kept in a byte buffer rather than a string so it can be zeroed with
+// Wipe once it is no longer needed; strings cannot be overwritten and linger
+// in memory until they are garbage collected.
+type Secret []byte
+
+// String keeps secrets out of logs and error messages by accident.
+func (s Secret) String() string {
+	return "[secret]"
+}
+
+// Wipe zeroes the secret.
+func (s Secret) Wipe() {
+	clear(s)
+}
+
+// MarshalText renders the secret as is in JSON and YAML results, rather than
+// base64 encoded.
+func (s Secret) MarshalText() ([]byte, error) {
+	return s, nil
+}
+
+// privateKeySecret returns the hex encoding of a private key without copying
+// it into a string.
+func privateKeySecret(privateKey *ecdsa.PrivateKey) Secret {
+	raw := crypto.FromECDSA(privateKey)
+	defer clear(raw)
+	s := make(Secret, hex.EncodedLen(len(raw)))
+	hex.Encode(s, raw)
+	return s
+}
+
+// PrivateKey parses the secret as a hex private key, with or without a 0x
+// prefix, without copying it into a string. Callers should wipe the key with
+// wipeKey once they are done with it.
+func (s Secret) PrivateKey() (*ecdsa.PrivateKey, error) {
+	h := bytes.TrimPrefix(bytes.TrimSpace(s), []byte("0x"))
+	raw := make([]byte, hex.DecodedLen(len(h)))
+	defer clear(raw)
+	if _, err := hex.Decode(raw, h); err != nil {
+		return nil, fmt.Errorf("invalid hex private key")
+	}
+	return crypto.ToECDSA(raw)
+}
+
+// wipeKey zeroes the scalar of a private key. The key must not be used
+// afterwards.
+func wipeKey(privateKey *ecdsa.PrivateKey) {
+	if privateKey != nil && privateKey.D != nil {
+		clear(privateKey.D.Bits())
+		privateKey.D.SetInt64(0)
+	}
+}
+
+// wiper is implemented by screens that hold secrets. The TUI calls wipe when
+// it leaves the screen, so nothing typed into it outlives it.
+type wiper interface {
+	wipe()
+	id() *screenID
+}
+
+// screenID tells screens apart. Screens are values copied by every Update,
+// so screens holding secrets embed the screenID they were opened with to let
+// the TUI tell a screen staying open from a fresh one taking over.
+type screenID struct {
+	ptr *screenID
+}
+
+// newScreenID returns the identity of a newly opened screen.
+func newScreenID() screenID {
+	return screenID{ptr: new(screenID)}
+}
+
+func (s screenID) id() *screenID {
+	return s.ptr
+}
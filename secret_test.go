@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestSecretPrivateKey(t *testing.T) {
+	for _, key := range []string{cliTestKey, " 0x" + cliTestKey + "\n"} {
+		privateKey, err := Secret(key).PrivateKey()
+		if err != nil {
+			t.Fatalf("PrivateKey(%q) failed: %v", key, err)
+		}
+		if address := crypto.PubkeyToAddress(privateKey.PublicKey).Hex(); address != cliTestAddress {
+			t.Errorf("Expected %s, got %s", cliTestAddress, address)
+		}
+
+		wipeKey(privateKey)
+		if privateKey.D.Sign() != 0 {
+			t.Error("Expected wipeKey to zero the key")
+		}
+	}
+
+	if _, err := Secret("0xzz").PrivateKey(); err == nil {
+		t.Error("Expected an error for invalid hex")
+	}
+	if _, err := Secret(cliTestKey[:10]).PrivateKey(); err == nil {
+		t.Error("Expected an error for a short key")
+	}
+}
+
+func TestSecretRedacted(t *testing.T) {
+	s := Secret(cliTestKey)
+	if formatted := fmt.Sprintf("%v %s", s, s); strings.Contains(formatted, cliTestKey[:8]) {
+		t.Errorf("Expected the secret to be redacted, got %q", formatted)
+	}
+	s.Wipe()
+	if strings.Trim(string(s), "\x00") != "" {
+		t.Errorf("Expected Wipe to zero the secret, got %q", string(s))
+	}
+}
@@ -0,0 +1,328 @@
+// sigformat.go
+
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var (
+	secp256k1N     = crypto.S256().Params().N
+	secp256k1HalfN = new(big.Int).Rsh(secp256k1N, 1)
+)
+
+// SignatureFormat identifies how a signature was encoded.
+type SignatureFormat int
+
+const (
+	// FormatCompact is the 64-byte EIP-2098 encoding with the y-parity
+	// folded into the top bit of s.
+	FormatCompact SignatureFormat = iota
+	// FormatStandard is the 65-byte r ‖ s ‖ v encoding with v in {0,1,27,28}.
+	FormatStandard
+	// FormatEIP155 is r ‖ s ‖ v with v = chainId*2 + 35 + yParity.
+	FormatEIP155
+)
+
+// String returns a human readable name for the format.
+func (f SignatureFormat) String() string {
+	switch f {
+	case FormatCompact:
+		return "EIP-2098 compact (64 bytes)"
+	case FormatStandard:
+		return "Standard (65 bytes)"
+	case FormatEIP155:
+		return "EIP-155 (v includes chain ID)"
+	default:
+		return fmt.Sprintf("SignatureFormat(%d)", int(f))
+	}
+}
+
+// SignatureComponents is a decoded secp256k1 signature.
+type SignatureComponents struct {
+	Format  SignatureFormat
+	R       *big.Int
+	S       *big.Int
+	V       *big.Int // v exactly as encoded; nil for compact signatures
+	YParity byte     // recovery ID, 0 or 1
+	ChainID *big.Int // only set for EIP-155 signatures
+}
+
+// ParseSignature splits a signature in any supported format into its
+// components.
+func ParseSignature(signature []byte) (*SignatureComponents, error) {
+	if len(signature) < 64 {
+		return nil, fmt.Errorf("invalid signature length")
+	}
+	sig := &SignatureComponents{
+		R: new(big.Int).SetBytes(signature[:32]),
+		S: new(big.Int).SetBytes(signature[32:64]),
+	}
+
+	if len(signature) == 64 {
+		// The top bit of the s-field carries the y-parity
+		sig.Format = FormatCompact
+		sig.YParity = signature[32] >> 7
+		sig.S.SetBit(sig.S, 255, 0)
+	} else {
+		if len(signature) > 64+32 {
+			return nil, fmt.Errorf("invalid signature length")
+		}
+		sig.V = new(big.Int).SetBytes(signature[64:])
+		switch {
+		case sig.V.IsUint64() && sig.V.Uint64() <= 1:
+			sig.Format = FormatStandard
+			sig.YParity = byte(sig.V.Uint64())
+		case sig.V.IsUint64() && (sig.V.Uint64() == 27 || sig.V.Uint64() == 28):
+			sig.Format = FormatStandard
+			sig.YParity = byte(sig.V.Uint64() - 27)
+		case sig.V.Cmp(big.NewInt(35)) >= 0:
+			sig.Format = FormatEIP155
+			rest := new(big.Int).Sub(sig.V, big.NewInt(35))
+			sig.YParity = byte(rest.Bit(0))
+			sig.ChainID = rest.Rsh(rest, 1)
+		default:
+			return nil, fmt.Errorf("invalid recovery value v=%s", sig.V)
+		}
+		if sig.Format == FormatStandard && len(signature) != 65 {
+			return nil, fmt.Errorf("invalid signature length")
+		}
+	}
+
+	if !crypto.ValidateSignatureValues(sig.YParity, sig.R, sig.S, false) {
+		return nil, fmt.Errorf("signature values are out of range")
+	}
+	return sig, nil
+}
+
+// HighS reports whether s is in the upper half of the curve order, which
+// makes the signature malleable and rejected by EIP-2 and OpenZeppelin's
+// ECDSA library.
+func (sig *SignatureComponents) HighS() bool {
+	return sig.S.Cmp(secp256k1HalfN) > 0
+}
+
+// Normalized returns the equivalent low-s signature.
+func (sig *SignatureComponents) Normalized() *SignatureComponents {
+	normalized := *sig
+	if sig.HighS() {
+		normalized.S = new(big.Int).Sub(secp256k1N, sig.S)
+		normalized.YParity ^= 1
+	}
+	return &normalized
+}
+
+// RecoveryBytes returns r ‖ s ‖ v with v as 0/1, as expected by ecrecover.
+func (sig *SignatureComponents) RecoveryBytes() []byte {
+	out := make([]byte, 65)
+	sig.R.FillBytes(out[:32])
+	sig.S.FillBytes(out[32:64])
+	out[64] = sig.YParity
+	return out
+}
+
+// Standard returns the 65-byte r ‖ s ‖ v encoding with v as 27/28.
+func (sig *SignatureComponents) Standard() []byte {
+	out := sig.RecoveryBytes()
+	out[64] += 27
+	return out
+}
+
+// Compact returns the 64-byte EIP-2098 encoding. High-s signatures cannot
+// be encoded because the top bit of s is needed for the y-parity.
+func (sig *SignatureComponents) Compact() ([]byte, error) {
+	if sig.HighS() {
+		return nil, fmt.Errorf("high-s signatures have no compact encoding")
+	}
+	out := sig.RecoveryBytes()[:64]
+	out[32] |= sig.YParity << 7
+	return out, nil
+}
+
+// decodeSignatureHex decodes a pasted hex signature, with or without the 0x
+// prefix and surrounding whitespace, without interpreting its bytes.
+func decodeSignatureHex(signatureHex string) ([]byte, error) {
+	h := strings.TrimSpace(signatureHex)
+	if !strings.HasPrefix(h, "0x") && !strings.HasPrefix(h, "0X") {
+		h = "0x" + h
+	}
+	signatureBytes, err := hexutil.Decode(h)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature format")
+	}
+	return signatureBytes, nil
+}
+
+// decodeSignature parses a hex-encoded signature in any supported format and
+// returns it in the 0/1 recovery form used by ecrecover.
+func decodeSignature(signatureHex string) ([]byte, error) {
+	signatureBytes, err := decodeSignatureHex(signatureHex)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := ParseSignature(signatureBytes)
+	if err != nil {
+		return nil, err
+	}
+	return sig.RecoveryBytes(), nil
+}
+
+// SignatureInspection describes the components, malleability and
+// equivalent encodings of a signature. The normalized encodings are only set
+// for high-s signatures.
+type SignatureInspection struct {
+	Format             string `json:"format" yaml:"format"`
+	R                  string `json:"r" yaml:"r"`
+	S                  string `json:"s" yaml:"s"`
+	V                  string `json:"v,omitempty" yaml:"v,omitempty"`
+	YParity            byte   `json:"yParity" yaml:"yParity"`
+	ChainID            string `json:"chainId,omitempty" yaml:"chainId,omitempty"`
+	HighS              bool   `json:"highS" yaml:"highS"`
+	Standard           string `json:"standard" yaml:"standard"`
+	Compact            string `json:"compact,omitempty" yaml:"compact,omitempty"`
+	NormalizedStandard string `json:"normalizedStandard,omitempty" yaml:"normalizedStandard,omitempty"`
+	NormalizedCompact  string `json:"normalizedCompact,omitempty" yaml:"normalizedCompact,omitempty"`
+}
+
+// InspectSignature decodes a hex-encoded signature and describes its
+// components, malleability and equivalent encodings.
+func InspectSignature(signatureHex string) (*SignatureInspection, error) {
+	signatureBytes, err := decodeSignatureHex(signatureHex)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := ParseSignature(signatureBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	inspection := &SignatureInspection{
+		Format:   sig.Format.String(),
+		R:        common.BigToHash(sig.R).Hex(),
+		S:        common.BigToHash(sig.S).Hex(),
+		YParity:  sig.YParity,
+		HighS:    sig.HighS(),
+		Standard: hexutil.Encode(sig.Standard()),
+	}
+	if sig.V != nil {
+		inspection.V = sig.V.String()
+	}
+	if sig.ChainID != nil {
+		inspection.ChainID = sig.ChainID.String()
+	}
+	if compact, err := sig.Compact(); err == nil {
+		inspection.Compact = hexutil.Encode(compact)
+	}
+	if sig.HighS() {
+		normalized := sig.Normalized()
+		compact, _ := normalized.Compact()
+		inspection.NormalizedStandard = hexutil.Encode(normalized.Standard())
+		inspection.NormalizedCompact = hexutil.Encode(compact)
+	}
+	return inspection, nil
+}
+
+// Text implements Result.
+func (r *SignatureInspection) Text() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Format           : %s\n", r.Format))
+	sb.WriteString(fmt.Sprintf("r                : %s\n", r.R))
+	sb.WriteString(fmt.Sprintf("s                : %s\n", r.S))
+	if r.V != "" {
+		sb.WriteString(fmt.Sprintf("v                : %s\n", r.V))
+	}
+	sb.WriteString(fmt.Sprintf("y-parity         : %d\n", r.YParity))
+	if r.ChainID != "" {
+		sb.WriteString(fmt.Sprintf("Chain ID         : %s\n", r.ChainID))
+	}
+	if r.HighS {
+		sb.WriteString("High s           : yes (malleable, rejected by EIP-2 and OpenZeppelin ECDSA)\n")
+	} else {
+		sb.WriteString("High s           : no\n")
+	}
+	sb.WriteString("\n")
+	sb.WriteString(fmt.Sprintf("Standard (65 bytes):\n%s\n", r.Standard))
+	if r.Compact != "" {
+		sb.WriteString(fmt.Sprintf("Compact (EIP-2098):\n%s\n", r.Compact))
+	}
+	if r.HighS {
+		sb.WriteString(fmt.Sprintf("Normalized low-s (65 bytes):\n%s\n", r.NormalizedStandard))
+		sb.WriteString(fmt.Sprintf("Normalized low-s (EIP-2098):\n%s\n", r.NormalizedCompact))
+	}
+	return sb.String()
+}
+
+// CopyFields implements Copyable.
+func (r *SignatureInspection) CopyFields() []CopyField {
+	fields := []CopyField{{Label: "Standard", Value: r.Standard}}
+	if r.Compact != "" {
+		fields = append(fields, CopyField{Label: "Compact", Value: r.Compact})
+	}
+	if r.HighS {
+		fields = append(fields,
+			CopyField{Label: "Normalized", Value: r.NormalizedStandard},
+			CopyField{Label: "Normalized Compact", Value: r.NormalizedCompact})
+	}
+	return fields
+}
+
+func init() {
+	registerTool(100, "Inspect Signature", newInspectScreen)
+}
+
+// inspectScreen decodes a signature and shows its encodings.
+type inspectScreen struct {
+	session   *session
+	signature textInput
+	content   string
+}
+
+func newInspectScreen(s *session) Screen {
+	return inspectScreen{session: s, signature: newTextInput()}
+}
+
+func (i inspectScreen) Init() tea.Cmd {
+	return nil
+}
+
+func (i inspectScreen) Update(msg tea.Msg) (Screen, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.Type {
+		case tea.KeyCtrlC, tea.KeyEsc:
+			return nil, nil
+		case tea.KeyEnter:
+			if i.signature.Value() == "" {
+				i.content = "Error: Signature cannot be empty."
+				return i, nil
+			}
+			inspection, err := InspectSignature(i.signature.Value())
+			if err != nil {
+				i.content = fmt.Sprintf("Error inspecting signature: %v", err)
+				return i, nil
+			}
+			return i.session.showResult(inspection), nil
+		default:
+			i.signature = i.signature.Update(msg)
+		}
+	}
+	return i, nil
+}
+
+func (i inspectScreen) View() string {
+	s := titleStyle.Render("Inspect Signature") + "\n\n"
+	s += "Enter the signature (64 or 65 bytes, in hex format) or press Esc to cancel:\n"
+	s += i.signature.View()
+	if i.content != "" {
+		s += "\n\n" + i.content + "\n\nPress Enter to continue..."
+	}
+	return s
+}
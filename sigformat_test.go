@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Vectors from the EIP-2098 specification.
+var eip2098Vectors = []struct {
+	message  string
+	standard string
+	compact  string
+}{
+	{
+		message:  "Hello World",
+		standard: "0x68a020a209d3d56c46f38cc50a33f704f4a9a10a59377f8dd762ac66910e9b907e865ad05c4035ab5792787d4a0297a43617ae897930a6fe4d822b8faea520641b",
+		compact:  "0x68a020a209d3d56c46f38cc50a33f704f4a9a10a59377f8dd762ac66910e9b907e865ad05c4035ab5792787d4a0297a43617ae897930a6fe4d822b8faea52064",
+	},
+	{
+		message:  "It's a small(er) world",
+		standard: "0x9328da16089fcba9bececa81663203989f2df5fe1faa6291a45381c81bd17f76139c6d6b623b42da56557e5e734a43dc83345ddfadec52cbe24d0cc64f5507931c",
+		compact:  "0x9328da16089fcba9bececa81663203989f2df5fe1faa6291a45381c81bd17f76939c6d6b623b42da56557e5e734a43dc83345ddfadec52cbe24d0cc64f550793",
+	},
+}
+
+const eip2098PrivateKey = "1234567890123456789012345678901234567890123456789012345678901234"
+
+func TestEIP2098Conversion(t *testing.T) {
+	for _, vector := range eip2098Vectors {
+		signature, err := SignMessage(eip2098PrivateKey, vector.message, SchemeEIP191)
+		if err != nil {
+			t.Fatalf("Failed to sign message: %v", err)
+		}
+		if signature != vector.standard {
+			t.Fatalf("Signature mismatch. Expected %s, got %s", vector.standard, signature)
+		}
+
+		standard, err := ParseSignature(hexutil.MustDecode(vector.standard))
+		if err != nil {
+			t.Fatalf("Failed to parse standard signature: %v", err)
+		}
+		compact, err := standard.Compact()
+		if err != nil {
+			t.Fatalf("Failed to compact signature: %v", err)
+		}
+		if hexutil.Encode(compact) != vector.compact {
+			t.Errorf("Compact mismatch. Expected %s, got %s", vector.compact, hexutil.Encode(compact))
+		}
+
+		parsed, err := ParseSignature(hexutil.MustDecode(vector.compact))
+		if err != nil {
+			t.Fatalf("Failed to parse compact signature: %v", err)
+		}
+		if parsed.Format != FormatCompact || hexutil.Encode(parsed.Standard()) != vector.standard {
+			t.Errorf("Compact signature did not expand to %s", vector.standard)
+		}
+	}
+}
+
+func TestVerifySignatureAcceptsAllFormats(t *testing.T) {
+	vector := eip2098Vectors[1]
+	signer, err := RecoverSigner(vector.message, vector.standard, SchemeEIP191)
+	if err != nil {
+		t.Fatalf("Failed to recover signer: %v", err)
+	}
+	address := signer.Address.Hex()
+
+	sig, err := ParseSignature(hexutil.MustDecode(vector.standard))
+	if err != nil {
+		t.Fatalf("Failed to parse signature: %v", err)
+	}
+
+	// v as 0/1, and EIP-155 style v for chain ID 1
+	raw := sig.RecoveryBytes()
+	eip155 := append(withoutV(raw), byte(1*2+35)+sig.YParity)
+
+	for name, signature := range map[string][]byte{
+		"standard": hexutil.MustDecode(vector.standard),
+		"compact":  hexutil.MustDecode(vector.compact),
+		"raw v":    raw,
+		"eip-155":  eip155,
+	} {
+		valid, err := VerifySignature(vector.message, hexutil.Encode(signature), address, SchemeEIP191)
+		if err != nil {
+			t.Fatalf("%s: failed to verify signature: %v", name, err)
+		}
+		if !valid {
+			t.Errorf("%s: signature verification failed", name)
+		}
+	}
+
+	parsed, err := ParseSignature(eip155)
+	if err != nil {
+		t.Fatalf("Failed to parse EIP-155 signature: %v", err)
+	}
+	if parsed.Format != FormatEIP155 || parsed.ChainID.Cmp(big.NewInt(1)) != 0 {
+		t.Errorf("EIP-155 signature parsed incorrectly: %+v", parsed)
+	}
+}
+
+func TestHighSSignature(t *testing.T) {
+	vector := eip2098Vectors[0]
+	sig, err := ParseSignature(hexutil.MustDecode(vector.standard))
+	if err != nil {
+		t.Fatalf("Failed to parse signature: %v", err)
+	}
+	if sig.HighS() {
+		t.Fatal("Reference signature unexpectedly has high s")
+	}
+
+	// Flip to the malleable twin: s' = n - s with the opposite parity
+	malleable := &SignatureComponents{
+		Format:  FormatStandard,
+		R:       sig.R,
+		S:       new(big.Int).Sub(secp256k1N, sig.S),
+		YParity: sig.YParity ^ 1,
+	}
+	parsed, err := ParseSignature(malleable.Standard())
+	if err != nil {
+		t.Fatalf("Failed to parse high-s signature: %v", err)
+	}
+	if !parsed.HighS() {
+		t.Fatal("High-s signature was not flagged")
+	}
+	if _, err := parsed.Compact(); err == nil {
+		t.Error("Expected an error compacting a high-s signature")
+	}
+	if !bytes.Equal(parsed.Normalized().Standard(), hexutil.MustDecode(vector.standard)) {
+		t.Error("Normalizing the high-s signature did not yield the original")
+	}
+}
+
+// withoutV returns a copy of the r ‖ s part of a signature.
+func withoutV(signature []byte) []byte {
+	return append([]byte{}, signature[:64]...)
+}
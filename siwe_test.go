@@ -0,0 +1,117 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func testSIWEMessage() *SIWEMessage {
+	return &SIWEMessage{
+		Scheme:         "https",
+		Domain:         "example.com",
+		Address:        "0x2c7536E3605D9C16a7a3D7b1898e529396a65c23",
+		Statement:      "I accept the ExampleOrg Terms of Service: https://example.com/tos",
+		URI:            "https://example.com/login",
+		Version:        "1",
+		ChainID:        1,
+		Nonce:          "32891756",
+		IssuedAt:       "2021-09-30T16:25:24Z",
+		ExpirationTime: "2021-10-30T16:25:24Z",
+		Resources:      []string{"ipfs://bafybeiemxf5abjwjbikoz4mc3a3dla6ual3jsgpdr4cjr3oz3evfyavhwq/", "https://example.com/my-web2-claim.json"},
+	}
+}
+
+func TestSIWEMessageRoundTrip(t *testing.T) {
+	expected := `https://example.com wants you to sign in with your Ethereum account:
+0x2c7536E3605D9C16a7a3D7b1898e529396a65c23
+
+I accept the ExampleOrg Terms of Service: https://example.com/tos
+
+URI: https://example.com/login
+Version: 1
+Chain ID: 1
+Nonce: 32891756
+Issued At: 2021-09-30T16:25:24Z
+Expiration Time: 2021-10-30T16:25:24Z
+Resources:
+- ipfs://bafybeiemxf5abjwjbikoz4mc3a3dla6ual3jsgpdr4cjr3oz3evfyavhwq/
+- https://example.com/my-web2-claim.json`
+
+	msg := testSIWEMessage()
+	if msg.String() != expected {
+		t.Fatalf("Rendered message mismatch.\nExpected:\n%s\nGot:\n%s", expected, msg.String())
+	}
+
+	parsed, err := ParseSIWEMessage(expected)
+	if err != nil {
+		t.Fatalf("Failed to parse message: %v", err)
+	}
+	if parsed.String() != expected {
+		t.Fatalf("Parsed message does not render back to the original:\n%s", parsed.String())
+	}
+
+	// Without a statement the address is followed by two empty lines
+	msg.Statement = ""
+	parsed, err = ParseSIWEMessage(msg.String())
+	if err != nil {
+		t.Fatalf("Failed to parse message without statement: %v", err)
+	}
+	if parsed.Statement != "" || parsed.URI != msg.URI {
+		t.Fatalf("Message without statement parsed incorrectly: %+v", parsed)
+	}
+}
+
+func TestParseSIWEMessageRejectsInvalidFields(t *testing.T) {
+	cases := map[string]func(*SIWEMessage){
+		"lowercase address": func(msg *SIWEMessage) { msg.Address = "0x2c7536e3605d9c16a7a3d7b1898e529396a65c23" },
+		"short nonce":       func(msg *SIWEMessage) { msg.Nonce = "abc" },
+		"relative URI":      func(msg *SIWEMessage) { msg.URI = "/login" },
+		"bad timestamp":     func(msg *SIWEMessage) { msg.IssuedAt = "yesterday" },
+		"bad version":       func(msg *SIWEMessage) { msg.Version = "2" },
+	}
+	for name, mutate := range cases {
+		msg := testSIWEMessage()
+		mutate(msg)
+		if _, err := ParseSIWEMessage(msg.String()); err == nil {
+			t.Errorf("%s: expected parse error", name)
+		}
+	}
+}
+
+func TestSignAndVerifySIWEMessage(t *testing.T) {
+	privateKeyHex := "4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+	msg := testSIWEMessage()
+	msg.NotBefore = "2021-09-30T17:00:00Z"
+
+	signature, err := SignSIWEMessage(privateKeyHex, msg)
+	if err != nil {
+		t.Fatalf("Failed to sign message: %v", err)
+	}
+
+	within := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := VerifySIWEMessage(msg.String(), signature, within); err != nil {
+		t.Fatalf("Failed to verify message: %v", err)
+	}
+
+	expired := time.Date(2021, 11, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := VerifySIWEMessage(msg.String(), signature, expired); !errors.Is(err, ErrSIWEExpired) {
+		t.Errorf("Expected ErrSIWEExpired, got %v", err)
+	}
+
+	early := time.Date(2021, 9, 30, 16, 30, 0, 0, time.UTC)
+	if _, err := VerifySIWEMessage(msg.String(), signature, early); !errors.Is(err, ErrSIWENotYetValid) {
+		t.Errorf("Expected ErrSIWENotYetValid, got %v", err)
+	}
+
+	msg.Nonce = "tampered123"
+	if _, err := VerifySIWEMessage(msg.String(), signature, within); !errors.Is(err, ErrSIWEInvalidSignature) {
+		t.Errorf("Expected ErrSIWEInvalidSignature, got %v", err)
+	}
+
+	// The signing key must own the message address
+	msg.Address = "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"
+	if _, err := SignSIWEMessage(privateKeyHex, msg); err == nil {
+		t.Error("Expected an error when signing for a different address")
+	}
+}
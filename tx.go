@@ -0,0 +1,447 @@
+// tx.go
+
+package main
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TxParams are the fields of a transaction to build and sign offline.
+type TxParams struct {
+	Type                 uint8 // types.LegacyTxType, types.AccessListTxType or types.DynamicFeeTxType
+	ChainID              *big.Int
+	Nonce                uint64
+	To                   *common.Address // nil for contract creation
+	Value                *big.Int
+	GasLimit             uint64
+	GasPrice             *big.Int // legacy and EIP-2930
+	MaxFeePerGas         *big.Int // EIP-1559
+	MaxPriorityFeePerGas *big.Int // EIP-1559
+	AccessList           types.AccessList
+	Data                 []byte
+}
+
+// BuildTransaction assembles an unsigned transaction of the requested type.
+func BuildTransaction(params TxParams) (*types.Transaction, error) {
+	if params.ChainID == nil || params.ChainID.Sign() <= 0 {
+		return nil, fmt.Errorf("chain ID must be a positive integer")
+	}
+	if params.GasLimit == 0 {
+		return nil, fmt.Errorf("gas limit cannot be zero")
+	}
+	value := params.Value
+	if value == nil {
+		value = new(big.Int)
+	}
+
+	switch params.Type {
+	case types.LegacyTxType:
+		if params.GasPrice == nil {
+			return nil, fmt.Errorf("gas price is required for legacy transactions")
+		}
+		if len(params.AccessList) > 0 {
+			return nil, fmt.Errorf("legacy transactions cannot have an access list")
+		}
+		return types.NewTx(&types.LegacyTx{
+			Nonce:    params.Nonce,
+			GasPrice: params.GasPrice,
+			Gas:      params.GasLimit,
+			To:       params.To,
+			Value:    value,
+			Data:     params.Data,
+		}), nil
+	case types.AccessListTxType:
+		if params.GasPrice == nil {
+			return nil, fmt.Errorf("gas price is required for EIP-2930 transactions")
+		}
+		return types.NewTx(&types.AccessListTx{
+			ChainID:    params.ChainID,
+			Nonce:      params.Nonce,
+			GasPrice:   params.GasPrice,
+			Gas:        params.GasLimit,
+			To:         params.To,
+			Value:      value,
+			Data:       params.Data,
+			AccessList: params.AccessList,
+		}), nil
+	case types.DynamicFeeTxType:
+		if params.MaxFeePerGas == nil || params.MaxPriorityFeePerGas == nil {
+			return nil, fmt.Errorf("max fee and max priority fee are required for EIP-1559 transactions")
+		}
+		if params.MaxPriorityFeePerGas.Cmp(params.MaxFeePerGas) > 0 {
+			return nil, fmt.Errorf("max priority fee cannot exceed max fee")
+		}
+		return types.NewTx(&types.DynamicFeeTx{
+			ChainID:    params.ChainID,
+			Nonce:      params.Nonce,
+			GasTipCap:  params.MaxPriorityFeePerGas,
+			GasFeeCap:  params.MaxFeePerGas,
+			Gas:        params.GasLimit,
+			To:         params.To,
+			Value:      value,
+			Data:       params.Data,
+			AccessList: params.AccessList,
+		}), nil
+	default:
+		return nil, fmt.Errorf("unsupported transaction type %d", params.Type)
+	}
+}
+
+// SignTransaction builds the transaction described by params and signs it
+// with the latest signer for its chain ID.
+func SignTransaction(privateKeyHex string, params TxParams) (*types.Transaction, error) {
+	privateKey, err := crypto.HexToECDSA(privateKeyHex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %v", err)
+	}
+	return signTransactionWithKey(privateKey, params)
+}
+
+// signTransactionWithKey builds and signs a transaction with an already
+// parsed private key.
+func signTransactionWithKey(privateKey *ecdsa.PrivateKey, params TxParams) (*types.Transaction, error) {
+	tx, err := BuildTransaction(params)
+	if err != nil {
+		return nil, err
+	}
+
+	signedTx, err := types.SignTx(tx, types.LatestSignerForChainID(params.ChainID), privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign transaction: %v", err)
+	}
+	return signedTx, nil
+}
+
+// unitDecimals maps the denominations accepted by ParseAmount to their
+// number of decimals.
+var unitDecimals = map[string]int{
+	"wei":    0,
+	"kwei":   3,
+	"mwei":   6,
+	"gwei":   9,
+	"szabo":  12,
+	"finney": 15,
+	"ether":  18,
+	"eth":    18,
+}
+
+// ParseAmount parses an amount such as "1.5 ether", "30gwei" or "1000" into
+// wei. Amounts without a unit are in wei.
+func ParseAmount(amount string) (*big.Int, error) {
+	amount = strings.ToLower(strings.TrimSpace(amount))
+	number := strings.TrimRightFunc(amount, func(r rune) bool { return r >= 'a' && r <= 'z' })
+	unit := strings.TrimSpace(amount[len(number):])
+	number = strings.TrimSpace(number)
+	if unit == "" {
+		unit = "wei"
+	}
+
+	decimals, ok := unitDecimals[unit]
+	if !ok {
+		return nil, fmt.Errorf("unknown unit %q", unit)
+	}
+
+	whole, fraction, _ := strings.Cut(number, ".")
+	if whole == "" && fraction == "" {
+		return nil, fmt.Errorf("invalid amount %q", amount)
+	}
+	if len(fraction) > decimals {
+		return nil, fmt.Errorf("amount %q has more decimals than %s allows", amount, unit)
+	}
+	digits := whole + fraction + strings.Repeat("0", decimals-len(fraction))
+	wei, ok := new(big.Int).SetString(digits, 10)
+	if !ok || wei.Sign() < 0 || strings.ContainsAny(digits, "+-") {
+		return nil, fmt.Errorf("invalid amount %q", amount)
+	}
+	return wei, nil
+}
+
+// FormatAmount formats a wei amount in the given unit, e.g. "1.5 ether".
+func FormatAmount(wei *big.Int, unit string) string {
+	decimals := unitDecimals[unit]
+	digits := new(big.Int).Abs(wei).String()
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+	whole, fraction := digits[:len(digits)-decimals], strings.TrimRight(digits[len(digits)-decimals:], "0")
+	if wei.Sign() < 0 {
+		whole = "-" + whole
+	}
+	if fraction == "" {
+		return whole + " " + unit
+	}
+	return whole + "." + fraction + " " + unit
+}
+
+// ParseTxType parses a transaction type name or number.
+func ParseTxType(s string) (uint8, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "0", "legacy":
+		return types.LegacyTxType, nil
+	case "1", "2930", "eip-2930", "access-list":
+		return types.AccessListTxType, nil
+	case "2", "1559", "eip-1559", "dynamic-fee", "":
+		return types.DynamicFeeTxType, nil
+	default:
+		return 0, fmt.Errorf("unknown transaction type %q", s)
+	}
+}
+
+// txTypeName returns a human readable name for a transaction type.
+func txTypeName(txType uint8) string {
+	switch txType {
+	case types.LegacyTxType:
+		return "Legacy (0)"
+	case types.AccessListTxType:
+		return "EIP-2930 access list (1)"
+	case types.DynamicFeeTxType:
+		return "EIP-1559 dynamic fee (2)"
+	case types.BlobTxType:
+		return "EIP-4844 blob (3)"
+	case types.SetCodeTxType:
+		return "EIP-7702 set code (4)"
+	default:
+		return fmt.Sprintf("Unknown (%d)", txType)
+	}
+}
+
+func init() {
+	registerTool(140, "Sign Transaction", newSignTxScreen)
+}
+
+// txFormFields are the labels of the fields on the transaction form.
+var txFormFields = []string{
+	"Private Key",
+	"Type (legacy, 2930, 1559)",
+	"Chain ID",
+	"Nonce",
+	"To (blank for contract creation)",
+	"Value (e.g. 0.1 ether, 1000 wei)",
+	"Gas Limit",
+	"Gas Price (legacy/2930, e.g. 20 gwei)",
+	"Max Fee Per Gas (1559)",
+	"Max Priority Fee Per Gas (1559)",
+	"Access List (JSON, optional)",
+	"Data (hex, optional)",
+}
+
+const (
+	txFieldKey = iota
+	txFieldType
+	txFieldChainID
+	txFieldNonce
+	txFieldTo
+	txFieldValue
+	txFieldGasLimit
+	txFieldGasPrice
+	txFieldMaxFee
+	txFieldMaxPriorityFee
+	txFieldAccessList
+	txFieldData
+)
+
+// newTxForm returns the initial values of the transaction form.
+func newTxForm() []string {
+	values := make([]string, len(txFormFields))
+	values[txFieldType] = "1559"
+	values[txFieldChainID] = "1"
+	values[txFieldNonce] = "0"
+	values[txFieldValue] = "0"
+	values[txFieldGasLimit] = "21000"
+	return values
+}
+
+// txParamsFromForm converts the transaction form values into TxParams.
+func txParamsFromForm(values []string) (TxParams, error) {
+	var params TxParams
+	var err error
+
+	if params.Type, err = ParseTxType(values[txFieldType]); err != nil {
+		return params, err
+	}
+
+	chainID, ok := new(big.Int).SetString(strings.TrimSpace(values[txFieldChainID]), 10)
+	if !ok {
+		return params, fmt.Errorf("invalid chain ID")
+	}
+	params.ChainID = chainID
+
+	if params.Nonce, err = strconv.ParseUint(strings.TrimSpace(values[txFieldNonce]), 10, 64); err != nil {
+		return params, fmt.Errorf("invalid nonce")
+	}
+
+	if to := strings.TrimSpace(values[txFieldTo]); to != "" {
+		if !common.IsHexAddress(to) {
+			return params, fmt.Errorf("invalid recipient address")
+		}
+		address := common.HexToAddress(to)
+		params.To = &address
+	}
+
+	if params.Value, err = ParseAmount(values[txFieldValue]); err != nil {
+		return params, fmt.Errorf("invalid value: %v", err)
+	}
+
+	if params.GasLimit, err = strconv.ParseUint(strings.TrimSpace(values[txFieldGasLimit]), 10, 64); err != nil {
+		return params, fmt.Errorf("invalid gas limit")
+	}
+
+	// optionalAmount parses a fee field that may be left blank.
+	optionalAmount := func(name, value string) (*big.Int, error) {
+		if strings.TrimSpace(value) == "" {
+			return nil, nil
+		}
+		amount, err := ParseAmount(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %v", name, err)
+		}
+		return amount, nil
+	}
+	if params.GasPrice, err = optionalAmount("gas price", values[txFieldGasPrice]); err != nil {
+		return params, err
+	}
+	if params.MaxFeePerGas, err = optionalAmount("max fee", values[txFieldMaxFee]); err != nil {
+		return params, err
+	}
+	if params.MaxPriorityFeePerGas, err = optionalAmount("max priority fee", values[txFieldMaxPriorityFee]); err != nil {
+		return params, err
+	}
+
+	if accessList := strings.TrimSpace(values[txFieldAccessList]); accessList != "" {
+		if err := json.Unmarshal([]byte(accessList), &params.AccessList); err != nil {
+			return params, fmt.Errorf("invalid access list: %v", err)
+		}
+	}
+
+	if data := strings.TrimSpace(values[txFieldData]); data != "" {
+		if !strings.HasPrefix(data, "0x") {
+			data = "0x" + data
+		}
+		if params.Data, err = hexutil.Decode(data); err != nil {
+			return params, fmt.Errorf("invalid data: %v", err)
+		}
+	}
+
+	return params, nil
+}
+
+// SignedTxResult is a transaction signed offline.
+type SignedTxResult struct {
+	Type string `json:"type" yaml:"type"`
+	From string `json:"from" yaml:"from"`
+	Hash string `json:"hash" yaml:"hash"`
+	Raw  string `json:"raw" yaml:"raw"`
+}
+
+// NewSignedTxResult encodes tx signed by from.
+func NewSignedTxResult(tx *types.Transaction, from common.Address) (*SignedTxResult, error) {
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode transaction: %v", err)
+	}
+	return &SignedTxResult{
+		Type: txTypeName(tx.Type()),
+		From: from.Hex(),
+		Hash: tx.Hash().Hex(),
+		Raw:  hexutil.Encode(raw),
+	}, nil
+}
+
+// Text implements Result.
+func (r *SignedTxResult) Text() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Transaction Type: %s\n", r.Type))
+	sb.WriteString(fmt.Sprintf("From            : %s\n", r.From))
+	sb.WriteString(fmt.Sprintf("Tx Hash         : %s\n", r.Hash))
+	sb.WriteString(fmt.Sprintf("\nRaw Transaction:\n%s", r.Raw))
+	return sb.String()
+}
+
+// CopyFields implements Copyable.
+func (r *SignedTxResult) CopyFields() []CopyField {
+	return []CopyField{
+		{Label: "Raw Transaction", Value: r.Raw},
+		{Label: "Tx Hash", Value: r.Hash},
+	}
+}
+
+// signTxScreen builds and signs a transaction offline.
+type signTxScreen struct {
+	screenID
+	session *session
+	form    form
+	content string
+}
+
+func newSignTxScreen(s *session) Screen {
+	return signTxScreen{screenID: newScreenID(), session: s, form: newForm(txFormFields, newTxForm(), txFieldKey)}
+}
+
+func (t signTxScreen) Init() tea.Cmd {
+	return nil
+}
+
+func (t signTxScreen) Update(msg tea.Msg) (Screen, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.Type {
+		case tea.KeyCtrlC, tea.KeyEsc:
+			return nil, nil
+		case tea.KeyEnter:
+			key := t.form.secret(txFieldKey)
+			defer key.Wipe()
+			privateKey, err := t.session.signingKey(key)
+			if err != nil {
+				t.content = signingKeyError(err)
+				return t, nil
+			}
+			defer wipeKey(privateKey)
+			params, err := txParamsFromForm(t.form.values)
+			if err != nil {
+				t.content = fmt.Sprintf("Error: %v", err)
+				return t, nil
+			}
+			tx, err := signTransactionWithKey(privateKey, params)
+			if err != nil {
+				t.content = fmt.Sprintf("Error signing transaction: %v", err)
+				return t, nil
+			}
+			result, err := NewSignedTxResult(tx, crypto.PubkeyToAddress(privateKey.PublicKey))
+			if err != nil {
+				t.content = fmt.Sprintf("Error: %v", err)
+				return t, nil
+			}
+			return t.session.showResult(result), nil
+		default:
+			t.form = t.form.update(msg)
+		}
+	}
+	return t, nil
+}
+
+func (t signTxScreen) wipe() {
+	t.form.wipe()
+}
+
+func (t signTxScreen) View() string {
+	s := titleStyle.Render("Sign Transaction") + "\n\n"
+	s += "Fill in the fields (Tab/Shift+Tab to move), Enter to sign or Esc to cancel:\n"
+	s += t.session.unlockedHint() + "\n"
+	s += t.form.view()
+	if t.content != "" {
+		s += "\n" + t.content
+	}
+	return s
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestParseAmount(t *testing.T) {
+	cases := map[string]string{
+		"1000":             "1000",
+		"1 ether":          "1000000000000000000",
+		"1.5ether":         "1500000000000000000",
+		"0.1 ETH":          "100000000000000000",
+		"30 gwei":          "30000000000",
+		"1.000000001 gwei": "1000000001",
+		".5 gwei":          "500000000",
+	}
+	for input, expected := range cases {
+		wei, err := ParseAmount(input)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", input, err)
+			continue
+		}
+		if wei.String() != expected {
+			t.Errorf("%q: expected %s, got %s", input, expected, wei)
+		}
+	}
+
+	for _, input := range []string{"", "ether", "1.5", "-1", "+1", "1 bitcoin", "1.0000000001 gwei"} {
+		if _, err := ParseAmount(input); err == nil {
+			t.Errorf("%q: expected an error", input)
+		}
+	}
+}
+
+func TestSignTransactionEIP155(t *testing.T) {
+	// Example transaction from the EIP-155 specification
+	to := common.HexToAddress("0x3535353535353535353535353535353535353535")
+	tx, err := SignTransaction("4646464646464646464646464646464646464646464646464646464646464646", TxParams{
+		Type:     types.LegacyTxType,
+		ChainID:  big.NewInt(1),
+		Nonce:    9,
+		To:       &to,
+		Value:    big.NewInt(1000000000000000000),
+		GasLimit: 21000,
+		GasPrice: big.NewInt(20000000000),
+	})
+	if err != nil {
+		t.Fatalf("Failed to sign transaction: %v", err)
+	}
+
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Failed to encode transaction: %v", err)
+	}
+	expected := "0xf86c098504a817c800825208943535353535353535353535353535353535353535880de0b6b3a76400008025a028ef61340bd939bc2195fe537567866003e1a15d3c71ff63e1590620aa636276a067cbe9d8997f761aecb703304b3800ccf555c9f3dc64214b297fb1966a3b6d83"
+	if hexutil.Encode(raw) != expected {
+		t.Fatalf("Raw transaction mismatch.\nExpected %s\nGot      %s", expected, hexutil.Encode(raw))
+	}
+}
+
+func TestSignTransactionTypes(t *testing.T) {
+	privateKeyHex := "4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+	privateKey, _ := crypto.HexToECDSA(privateKeyHex)
+	from := crypto.PubkeyToAddress(privateKey.PublicKey)
+
+	values := newTxForm()
+	values[txFieldTo] = "0x3535353535353535353535353535353535353535"
+	values[txFieldValue] = "0.01 ether"
+	values[txFieldGasPrice] = "20 gwei"
+	values[txFieldMaxFee] = "30 gwei"
+	values[txFieldMaxPriorityFee] = "2 gwei"
+	values[txFieldChainID] = "11155111"
+	values[txFieldData] = "0xdeadbeef"
+
+	for _, txType := range []string{"legacy", "2930", "1559"} {
+		values[txFieldType] = txType
+		values[txFieldAccessList] = ""
+		if txType != "legacy" {
+			values[txFieldAccessList] = `[{"address":"0x3535353535353535353535353535353535353535","storageKeys":["0x0000000000000000000000000000000000000000000000000000000000000001"]}]`
+		}
+
+		params, err := txParamsFromForm(values)
+		if err != nil {
+			t.Fatalf("%s: failed to parse form: %v", txType, err)
+		}
+		tx, err := SignTransaction(privateKeyHex, params)
+		if err != nil {
+			t.Fatalf("%s: failed to sign transaction: %v", txType, err)
+		}
+		if tx.Type() != params.Type {
+			t.Errorf("%s: expected type %d, got %d", txType, params.Type, tx.Type())
+		}
+
+		raw, _ := tx.MarshalBinary()
+		var decoded types.Transaction
+		if err := decoded.UnmarshalBinary(raw); err != nil {
+			t.Fatalf("%s: failed to decode raw transaction: %v", txType, err)
+		}
+		sender, err := types.Sender(types.LatestSignerForChainID(big.NewInt(11155111)), &decoded)
+		if err != nil {
+			t.Fatalf("%s: failed to recover sender: %v", txType, err)
+		}
+		if sender != from {
+			t.Errorf("%s: sender mismatch, got %s", txType, sender.Hex())
+		}
+		if decoded.Value().String() != "10000000000000000" || hexutil.Encode(decoded.Data()) != "0xdeadbeef" {
+			t.Errorf("%s: decoded fields mismatch", txType)
+		}
+	}
+
+	// Fee fields must match the transaction type
+	values[txFieldType] = "1559"
+	values[txFieldMaxFee] = ""
+	params, err := txParamsFromForm(values)
+	if err != nil {
+		t.Fatalf("Failed to parse form: %v", err)
+	}
+	if _, err := SignTransaction(privateKeyHex, params); err == nil {
+		t.Error("Expected an error for an EIP-1559 transaction without a max fee")
+	}
+}
@@ -0,0 +1,375 @@
+// vanity.go
+
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// VanityPattern describes the addresses a vanity search accepts. Prefix and
+// Suffix are hex strings and Regex is matched against the 40 hex characters
+// of the address. With CaseSensitive all three are compared against the
+// EIP-55 checksummed form instead of the lowercase one.
+type VanityPattern struct {
+	Prefix        string
+	Suffix        string
+	Regex         *regexp.Regexp
+	CaseSensitive bool
+}
+
+var hexPattern = regexp.MustCompile(`^[0-9a-fA-F]*$`)
+
+// NewVanityPattern validates and builds a VanityPattern. An empty regex
+// matches everything.
+func NewVanityPattern(prefix, suffix, regex string, caseSensitive bool) (*VanityPattern, error) {
+	prefix = strings.TrimPrefix(strings.TrimSpace(prefix), "0x")
+	suffix = strings.TrimSpace(suffix)
+	if !hexPattern.MatchString(prefix) {
+		return nil, fmt.Errorf("prefix must only contain hex characters")
+	}
+	if !hexPattern.MatchString(suffix) {
+		return nil, fmt.Errorf("suffix must only contain hex characters")
+	}
+	if len(prefix)+len(suffix) > common.AddressLength*2 {
+		return nil, fmt.Errorf("prefix and suffix are longer than an address")
+	}
+
+	pattern := &VanityPattern{Prefix: prefix, Suffix: suffix, CaseSensitive: caseSensitive}
+	if !caseSensitive {
+		pattern.Prefix = strings.ToLower(prefix)
+		pattern.Suffix = strings.ToLower(suffix)
+	}
+	if regex = strings.TrimSpace(regex); regex != "" {
+		re, err := regexp.Compile(regex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex: %v", err)
+		}
+		pattern.Regex = re
+	}
+	if pattern.Prefix == "" && pattern.Suffix == "" && pattern.Regex == nil {
+		return nil, fmt.Errorf("at least one of prefix, suffix or regex is required")
+	}
+	return pattern, nil
+}
+
+// Matches reports whether address satisfies the pattern.
+func (p *VanityPattern) Matches(address common.Address) bool {
+	lower := fmt.Sprintf("%x", address[:])
+	if !strings.HasPrefix(lower, strings.ToLower(p.Prefix)) || !strings.HasSuffix(lower, strings.ToLower(p.Suffix)) {
+		return false
+	}
+
+	target := lower
+	if p.CaseSensitive {
+		// Checksumming costs another Keccak, so only do it for candidates
+		// that already match case-insensitively.
+		target = address.Hex()[2:]
+		if !strings.HasPrefix(target, p.Prefix) || !strings.HasSuffix(target, p.Suffix) {
+			return false
+		}
+	}
+	return p.Regex == nil || p.Regex.MatchString(target)
+}
+
+// Difficulty returns the expected number of attempts needed to find a match
+// for the prefix and suffix. Regexes cannot be estimated and are ignored.
+func (p *VanityPattern) Difficulty() float64 {
+	difficulty := 1.0
+	for _, c := range p.Prefix + p.Suffix {
+		difficulty *= 16
+		// Each letter of a checksummed address is upper case half the time
+		if p.CaseSensitive && !('0' <= c && c <= '9') {
+			difficulty *= 2
+		}
+	}
+	return difficulty
+}
+
+// VanityResult is the key found by a vanity search.
+type VanityResult struct {
+	PrivateKey *ecdsa.PrivateKey
+	Address    common.Address
+	Attempts   uint64
+	Duration   time.Duration
+}
+
+// SearchVanity generates random keys on workers goroutines until one has an
+// address matching pattern or ctx is cancelled. Every attempt is added to
+// attempts, which callers may read concurrently to report progress.
+func SearchVanity(ctx context.Context, pattern *VanityPattern, workers int, attempts *atomic.Uint64) (*VanityResult, error) {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if attempts == nil {
+		attempts = new(atomic.Uint64)
+	}
+	start := time.Now()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		once   sync.Once
+		found  *ecdsa.PrivateKey
+		errOut error
+		wg     sync.WaitGroup
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				privateKey, err := crypto.GenerateKey()
+				if err != nil {
+					once.Do(func() { errOut = err })
+					cancel()
+					return
+				}
+				attempts.Add(1)
+				if pattern.Matches(crypto.PubkeyToAddress(privateKey.PublicKey)) {
+					once.Do(func() { found = privateKey })
+					cancel()
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if errOut != nil {
+		return nil, fmt.Errorf("failed to generate key: %v", errOut)
+	}
+	if found == nil {
+		return nil, context.Cause(ctx)
+	}
+	return &VanityResult{
+		PrivateKey: found,
+		Address:    crypto.PubkeyToAddress(found.PublicKey),
+		Attempts:   attempts.Load(),
+		Duration:   time.Since(start),
+	}, nil
+}
+
+// searchProgress tracks a running brute-force search of the TUI.
+type searchProgress struct {
+	cancel     context.CancelFunc
+	attempts   *atomic.Uint64
+	start      time.Time
+	difficulty float64
+	workers    int
+}
+
+// searchTickMsg asks a search screen to refresh its progress.
+type searchTickMsg struct{}
+
+// vanityResultMsg carries the outcome of a vanity search.
+type vanityResultMsg struct {
+	search *searchProgress
+	result *VanityResult
+	err    error
+}
+
+func searchTick() tea.Cmd {
+	return tea.Tick(500*time.Millisecond, func(time.Time) tea.Msg { return searchTickMsg{} })
+}
+
+func init() {
+	registerTool(30, "Generate Vanity Address", newVanityScreen)
+}
+
+// vanityFormFields are the labels of the fields on the vanity form.
+var vanityFormFields = []string{
+	"Prefix (hex)",
+	"Suffix (hex)",
+	"Regex (optional)",
+	"Case Sensitive EIP-55 (y/n)",
+}
+
+const (
+	vanityFieldPrefix = iota
+	vanityFieldSuffix
+	vanityFieldRegex
+	vanityFieldCaseSensitive
+)
+
+// newVanityForm returns the initial values of the vanity form.
+func newVanityForm() []string {
+	values := make([]string, len(vanityFormFields))
+	values[vanityFieldCaseSensitive] = "n"
+	return values
+}
+
+// vanityScreen searches for a vanity address in the background.
+type vanityScreen struct {
+	session *session
+	form    form
+	search  *searchProgress
+	content string
+}
+
+func newVanityScreen(s *session) Screen {
+	return vanityScreen{session: s, form: newForm(vanityFormFields, newVanityForm())}
+}
+
+func (v vanityScreen) Init() tea.Cmd {
+	return nil
+}
+
+func (v vanityScreen) Update(msg tea.Msg) (Screen, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.Type {
+		case tea.KeyCtrlC, tea.KeyEsc:
+			if v.search != nil {
+				// Cancel the running search but stay on the form
+				v.search.cancel()
+				v.search = nil
+				v.content = "Search cancelled."
+				return v, nil
+			}
+			return nil, nil
+		case tea.KeyEnter:
+			if v.search != nil {
+				return v, nil
+			}
+			values := v.form.values
+			caseSensitive := strings.EqualFold(strings.TrimSpace(values[vanityFieldCaseSensitive]), "y")
+			pattern, err := NewVanityPattern(values[vanityFieldPrefix], values[vanityFieldSuffix], values[vanityFieldRegex], caseSensitive)
+			if err != nil {
+				v.content = fmt.Sprintf("Error: %v", err)
+				return v, nil
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			search := &searchProgress{
+				cancel:     cancel,
+				attempts:   new(atomic.Uint64),
+				start:      time.Now(),
+				difficulty: pattern.Difficulty(),
+				workers:    runtime.NumCPU(),
+			}
+			v.search = search
+			v.content = ""
+			return v, tea.Batch(searchTick(), func() tea.Msg {
+				result, err := SearchVanity(ctx, pattern, search.workers, search.attempts)
+				return vanityResultMsg{search: search, result: result, err: err}
+			})
+		default:
+			if v.search == nil {
+				v.form = v.form.update(msg)
+			}
+		}
+	case searchTickMsg:
+		if v.search != nil {
+			return v, searchTick()
+		}
+	case vanityResultMsg:
+		if msg.result != nil {
+			defer wipeKey(msg.result.PrivateKey)
+		}
+		if v.search == nil || v.search != msg.search {
+			// Result of a search that was already cancelled
+			return v, nil
+		}
+		v.search.cancel()
+		v.search = nil
+		if msg.err != nil {
+			v.content = fmt.Sprintf("Error: %v", msg.err)
+			return v, nil
+		}
+		return v.session.showResult(NewVanityAddressResult(msg.result)), nil
+	}
+	return v, nil
+}
+
+func (v vanityScreen) View() string {
+	s := titleStyle.Render("Generate Vanity Address") + "\n\n"
+	if v.search == nil {
+		s += "Fill in the fields (Tab/Shift+Tab to move), Enter to start searching or Esc to cancel:\n\n"
+		s += v.form.view()
+		if v.content != "" {
+			s += "\n" + v.content
+		}
+		return s
+	}
+
+	s += v.form.view() + "\n"
+	s += formatSearchProgress(v.search)
+	s += "\nSearching... press Esc to cancel."
+	return s
+}
+
+func formatSearchProgress(search *searchProgress) string {
+	attempts := search.attempts.Load()
+	elapsed := time.Since(search.start)
+	rate := float64(attempts) / elapsed.Seconds()
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Workers          : %d\n", search.workers))
+	sb.WriteString(fmt.Sprintf("Attempts         : %d\n", attempts))
+	sb.WriteString(fmt.Sprintf("Speed            : %.0f addresses/sec\n", rate))
+	sb.WriteString(fmt.Sprintf("Elapsed          : %s\n", elapsed.Round(time.Second)))
+	sb.WriteString(fmt.Sprintf("Difficulty       : 1 in %.0f\n", search.difficulty))
+	if rate > 0 {
+		// Attempts are independent, so the 50% point is ln(2) * difficulty
+		eta := time.Duration(math.Ln2 * search.difficulty / rate * float64(time.Second))
+		sb.WriteString(fmt.Sprintf("50%% Probability  : %s\n", eta.Round(time.Second)))
+		probability := 1 - math.Exp(-float64(attempts)/search.difficulty)
+		sb.WriteString(fmt.Sprintf("Found by now     : %.1f%% chance\n", probability*100))
+	}
+	return sb.String()
+}
+
+// VanityAddressResult is the key found by a vanity address search.
+type VanityAddressResult struct {
+	Address    string `json:"address" yaml:"address"`
+	PrivateKey Secret `json:"privateKey" yaml:"privateKey"`
+	Attempts   uint64 `json:"attempts" yaml:"attempts"`
+	Duration   string `json:"duration" yaml:"duration"`
+}
+
+// NewVanityAddressResult converts the outcome of a vanity address search.
+func NewVanityAddressResult(result *VanityResult) *VanityAddressResult {
+	return &VanityAddressResult{
+		Address:    result.Address.Hex(),
+		PrivateKey: privateKeySecret(result.PrivateKey),
+		Attempts:   result.Attempts,
+		Duration:   result.Duration.Round(time.Millisecond).String(),
+	}
+}
+
+// Text implements Result.
+func (r *VanityAddressResult) Text() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Vanity Address: %s\n", r.Address))
+	sb.WriteString(fmt.Sprintf("Private Key   : %s\n", string(r.PrivateKey)))
+	sb.WriteString(fmt.Sprintf("Attempts      : %d in %s\n", r.Attempts, r.Duration))
+	sb.WriteString("\nWARNING: Store this private key securely. Never share it with anyone!")
+	return sb.String()
+}
+
+// CopyFields implements Copyable.
+func (r *VanityAddressResult) CopyFields() []CopyField {
+	return []CopyField{
+		{Label: "Private Key", Value: string(r.PrivateKey), Secret: true},
+		{Label: "Address", Value: r.Address},
+	}
+}
+
+func (r *VanityAddressResult) wipe() {
+	r.PrivateKey.Wipe()
+}
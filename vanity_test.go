@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestVanityPatternMatches(t *testing.T) {
+	address := common.HexToAddress("0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed")
+
+	tests := []struct {
+		prefix, suffix, regex string
+		caseSensitive         bool
+		want                  bool
+	}{
+		{"5aae", "", "", false, true},
+		{"0x5AAE", "", "", false, true},
+		{"", "BEAED", "", false, true},
+		{"5aAe", "eAed", "", true, true},
+		{"5aae", "", "", true, false},
+		{"", "", "^5a+e", false, true},
+		{"", "", "^5aA", true, true},
+		{"", "", "^5aA", false, false},
+		{"dead", "", "", false, false},
+	}
+	for _, tt := range tests {
+		pattern, err := NewVanityPattern(tt.prefix, tt.suffix, tt.regex, tt.caseSensitive)
+		if err != nil {
+			t.Fatalf("NewVanityPattern(%q, %q, %q) failed: %v", tt.prefix, tt.suffix, tt.regex, err)
+		}
+		if got := pattern.Matches(address); got != tt.want {
+			t.Errorf("Pattern %q/%q/%q (case sensitive %v): expected %v, got %v", tt.prefix, tt.suffix, tt.regex, tt.caseSensitive, tt.want, got)
+		}
+	}
+}
+
+func TestNewVanityPatternInvalid(t *testing.T) {
+	for _, args := range [][3]string{
+		{"", "", ""},
+		{"xyz", "", ""},
+		{"", "g0", ""},
+		{"", "", "("},
+		{strings.Repeat("a", 41), "", ""},
+	} {
+		if _, err := NewVanityPattern(args[0], args[1], args[2], false); err == nil {
+			t.Errorf("Expected an error for %q", args)
+		}
+	}
+}
+
+func TestVanityDifficulty(t *testing.T) {
+	pattern, _ := NewVanityPattern("ab", "1", "", false)
+	if d := pattern.Difficulty(); d != 4096 {
+		t.Errorf("Expected difficulty 4096, got %.0f", d)
+	}
+	pattern, _ = NewVanityPattern("aB", "1", "", true)
+	if d := pattern.Difficulty(); d != 16384 {
+		t.Errorf("Expected difficulty 16384, got %.0f", d)
+	}
+}
+
+func TestSearchVanity(t *testing.T) {
+	pattern, err := NewVanityPattern("a", "", "", false)
+	if err != nil {
+		t.Fatalf("NewVanityPattern failed: %v", err)
+	}
+	attempts := new(atomic.Uint64)
+	result, err := SearchVanity(context.Background(), pattern, 2, attempts)
+	if err != nil {
+		t.Fatalf("SearchVanity failed: %v", err)
+	}
+	if !pattern.Matches(result.Address) {
+		t.Errorf("Address %s does not match the pattern", result.Address.Hex())
+	}
+	if crypto.PubkeyToAddress(result.PrivateKey.PublicKey) != result.Address {
+		t.Error("Private key does not belong to the address")
+	}
+	if result.Attempts == 0 || attempts.Load() < result.Attempts {
+		t.Errorf("Unexpected attempt count %d (counter %d)", result.Attempts, attempts.Load())
+	}
+}
+
+func TestSearchVanityCancel(t *testing.T) {
+	pattern, err := NewVanityPattern(strings.Repeat("0", 40), "", "", false)
+	if err != nil {
+		t.Fatalf("NewVanityPattern failed: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := SearchVanity(ctx, pattern, 2, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestVanityStaleResult(t *testing.T) {
+	screen := newVanityScreen(&session{})
+	screen, _ = screen.Update(runes("deadbeef"))
+
+	screen, _ = screen.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	first := screen.(vanityScreen).search
+	screen, _ = screen.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	screen, _ = screen.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	second := screen.(vanityScreen).search
+	if first == nil || second == nil || first == second {
+		t.Fatal("Expected Enter to start a new search after cancelling")
+	}
+	defer second.cancel()
+
+	// The cancelled search finishing must not end the new one
+	screen, _ = screen.Update(vanityResultMsg{search: first, err: context.Canceled})
+	if v, ok := screen.(vanityScreen); !ok || v.search != second {
+		t.Errorf("Expected the new search to keep running, got %#v", screen)
+	}
+}